@@ -0,0 +1,282 @@
+package yacheckout
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+//Allocation errors
+var (
+	ErrAllocationExceedsTotal = errors.New("yacheckout: allocated amount exceeds items total")
+	ErrAllocationTooSmall     = errors.New("yacheckout: allocated amount is too small for item quantities")
+)
+
+//AllocateItems func return copies of items with totals proportional to original ones and summing exactly to total,
+//for example receipt of partial refund. Shares are rounded by the largest remainder method,
+//items whose share can't give a positive unit price are dropped and their share is spread over the rest
+func AllocateItems(items []Item, total Amount) ([]Item, error) {
+	return allocateItems(items, total, false)
+}
+
+//DiscountItems func return copies of all items with discount spread proportionally.
+//Shares are rounded by the largest remainder method and raised where needed to keep a positive unit price
+func DiscountItems(items []Item, discount Amount) ([]Item, error) {
+
+	total, err := itemsTotal(items, discount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rest, err := total.Sub(discount)
+	if err != nil {
+		return nil, err
+	}
+
+	if discount.Sign() < 0 || rest.Sign() < 0 {
+		return nil, ErrAllocationExceedsTotal
+	}
+
+	return allocateItems(items, rest, true)
+}
+
+//Discounted func return copy of receipt with discount spread over its items
+func (receipt *Receipt) Discounted(discount Amount) (*Receipt, error) {
+
+	items, err := DiscountItems(receipt.Items, discount)
+	if err != nil {
+		return nil, err
+	}
+
+	r := receipt.template()
+	r.Items = items
+	return r, nil
+}
+
+//RefundReceipt func return receipt of partial refund of amount for Refund.Receipt,
+//amount is spread over items of receipt proportionally
+func (receipt *Receipt) RefundReceipt(amount Amount) (*Receipt, error) {
+
+	items, err := AllocateItems(receipt.Items, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	r := receipt.template()
+	r.Items = items
+	return r, nil
+}
+
+//template func return copy of receipt customer and tax system without items
+func (receipt *Receipt) template() *Receipt {
+
+	r := &Receipt{TaxSystemCode: receipt.TaxSystemCode}
+	if receipt.Customer != nil {
+		customer := *receipt.Customer
+		r.Customer = &customer
+	}
+
+	return r
+}
+
+func itemsTotal(items []Item, currency string) (total Amount, err error) {
+
+	total.Currency = currency
+	for i := range items {
+		var t Amount
+		if t, err = items[i].Total(); err != nil {
+			return
+		}
+		if total, err = total.Add(t); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+//allocateItems func spreads total over items, keepAll raises small shares instead of dropping items
+func allocateItems(items []Item, total Amount, keepAll bool) (allocated []Item, err error) {
+
+	for i := range items {
+		if items[i].Quantity.Sign() <= 0 {
+			return nil, &ValidationError{Parameter: "items[" + strconv.Itoa(i) + "].quantity", Description: "must be positive"}
+		}
+	}
+
+	current, err := itemsTotal(items, total.Currency)
+	if err != nil {
+		return
+	}
+
+	if cmp, _ := total.Cmp(current); total.Sign() < 0 || cmp > 0 {
+		return nil, ErrAllocationExceedsTotal
+	}
+
+	weights := make([]int64, len(items))
+	minimums := make([]int64, len(items))
+	for i := range items {
+		var t Amount
+		if t, err = items[i].Total(); err != nil {
+			return
+		}
+		weights[i] = t.Minor
+		minimums[i] = minimumShare(items[i].Quantity)
+	}
+
+	var shares []Amount
+	if keepAll {
+		shares, err = raiseShares(total, weights, minimums)
+	} else {
+		shares, err = dropShares(total, weights, minimums)
+	}
+	if err != nil {
+		return
+	}
+
+	allocated = []Item{}
+	for i, share := range shares {
+		if share.IsZero() {
+			continue
+		}
+		fitted, err := fitItem(items[i], share)
+		if err != nil {
+			return nil, err
+		}
+		allocated = append(allocated, fitted...)
+	}
+
+	return
+}
+
+//raiseShares func allocates total by weights raising shares below minimums at the expense of the largest slack
+func raiseShares(total Amount, weights, minimums []int64) ([]Amount, error) {
+
+	shares, err := total.Allocate(weights...)
+	if err != nil {
+		return nil, err
+	}
+
+	var deficit int64
+	for i := range shares {
+		if shares[i].Minor < minimums[i] {
+			deficit += minimums[i] - shares[i].Minor
+			shares[i].Minor = minimums[i]
+		}
+	}
+
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return shares[order[a]].Minor-minimums[order[a]] > shares[order[b]].Minor-minimums[order[b]]
+	})
+
+	for _, i := range order {
+		slack := shares[i].Minor - minimums[i]
+		if slack > deficit {
+			slack = deficit
+		}
+		shares[i].Minor -= slack
+		deficit -= slack
+	}
+
+	if deficit > 0 {
+		return nil, ErrAllocationTooSmall
+	}
+
+	return shares, nil
+}
+
+//dropShares func allocates total by weights zeroing weights of shares below minimums until all fit
+func dropShares(total Amount, weights, minimums []int64) ([]Amount, error) {
+
+	weights = append([]int64(nil), weights...)
+
+	for !total.IsZero() {
+		shares, err := total.Allocate(weights...)
+		if err != nil {
+			return nil, ErrAllocationTooSmall
+		}
+
+		dropped := false
+		for i := range shares {
+			if !shares[i].IsZero() && shares[i].Minor < minimums[i] {
+				weights[i] = 0
+				dropped = true
+			}
+		}
+
+		if !dropped {
+			return shares, nil
+		}
+	}
+
+	return make([]Amount, len(weights)), nil
+}
+
+//minimumShare func return smallest total in minor units giving positive unit price for quantity
+func minimumShare(quantity Decimal) int64 {
+
+	unit := decimalUnit(quantity.scale)
+	if min := (quantity.coef + unit - 1) / unit; min > 1 {
+		return min
+	}
+
+	return 1
+}
+
+//fitItem func return item with unit price such that its total equals share,
+//split into one unit carrying the remainder and the rest when quantity doesn't allow it
+func fitItem(item Item, share Amount) (fitted []Item, err error) {
+
+	unit := decimalUnit(item.Quantity.scale)
+	if item.Amount, err = share.MulRatio(unit, item.Quantity.coef); err != nil {
+		return
+	}
+
+	total, err := item.Total()
+	if err != nil {
+		return
+	}
+	if total.Equal(share) {
+		return []Item{item}, nil
+	}
+
+	rest := item
+	rest.Quantity = Decimal{coef: item.Quantity.coef - unit, scale: item.Quantity.scale}
+	if rest.Quantity.Sign() <= 0 {
+		return nil, ErrAllocationTooSmall
+	}
+
+	//unit price rounded down keeps rest total below share
+	price := new(big.Int).Mul(big.NewInt(share.Minor), big.NewInt(unit))
+	if rest.Amount, err = amountOf(price.Quo(price, big.NewInt(item.Quantity.coef)), share.Currency); err != nil {
+		return
+	}
+
+	if total, err = rest.Total(); err != nil {
+		return
+	}
+
+	last := item
+	last.Quantity = Decimal{coef: 1}
+	if last.Amount, err = share.Sub(total); err != nil {
+		return
+	}
+
+	return []Item{rest, last}, nil
+}
+
+//decimalUnit func return 10^scale
+func decimalUnit(scale int) int64 {
+
+	unit := int64(1)
+	for i := 0; i < scale; i++ {
+		unit *= 10
+	}
+
+	return unit
+}
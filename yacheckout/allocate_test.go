@@ -0,0 +1,183 @@
+package yacheckout
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+//item func return item of price in minor units and quantity such as "2.5"
+func item(t *testing.T, price int64, quantity string) Item {
+
+	t.Helper()
+
+	q, err := ParseDecimal(quantity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Item{Description: quantity, Amount: NewAmount(price, "RUB"), Quantity: q, VatCode: VAT20}
+}
+
+//itemsString func return items as "price x quantity" list
+func itemsString(items []Item) string {
+
+	s := make([]string, len(items))
+	for i := range items {
+		s[i] = items[i].Amount.Value() + " x " + items[i].Quantity.String()
+	}
+
+	return strings.Join(s, ", ")
+}
+
+//checkItems func checks that items have positive prices and sum to total
+func checkItems(t *testing.T, items []Item, total int64) {
+
+	t.Helper()
+
+	sum, err := itemsTotal(items, "RUB")
+	if err != nil || sum.Minor != total {
+		t.Errorf("items %s sum to %v, %v; want %d", itemsString(items), sum, err, total)
+	}
+
+	for i := range items {
+		if items[i].Amount.Sign() <= 0 {
+			t.Errorf("item %s has non-positive price", itemsString(items[i:i+1]))
+		}
+	}
+}
+
+func TestAllocateItems(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		items []Item
+		total int64
+		want  string
+		err   error
+	}{
+		{"proportional", []Item{item(t, 10000, "1"), item(t, 5000, "2")}, 10000, "50.00 x 1, 25.00 x 2", nil},
+		{"whole", []Item{item(t, 10000, "1"), item(t, 5000, "2")}, 20000, "100.00 x 1, 50.00 x 2", nil},
+		{"split remainder", []Item{item(t, 1000, "3")}, 1000, "3.33 x 2, 3.34 x 1", nil},
+		{"fractional quantity", []Item{item(t, 1000, "0.5")}, 300, "6.00 x 0.5", nil},
+		{"small quantity", []Item{item(t, 100000, "0.001")}, 1, "10.00 x 0.001", nil},
+		{"split fractional quantity", []Item{item(t, 1000, "2.5")}, 1001, "4.00 x 1.5, 4.01 x 1", nil},
+		{"dropped small share", []Item{item(t, 100000, "1"), item(t, 1, "3")}, 50, "0.50 x 1", nil},
+		{"zero", []Item{item(t, 10000, "1")}, 0, "", nil},
+		{"exceeds", []Item{item(t, 10000, "1")}, 10001, "", ErrAllocationExceedsTotal},
+		{"negative", []Item{item(t, 10000, "1")}, -1, "", ErrAllocationExceedsTotal},
+	}
+
+	for _, tt := range tests {
+		items, err := AllocateItems(tt.items, NewAmount(tt.total, "RUB"))
+		if err != tt.err {
+			t.Errorf("%s: AllocateItems = %s, %v; want %v", tt.name, itemsString(items), err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := itemsString(items); got != tt.want {
+			t.Errorf("%s: AllocateItems = %s; want %s", tt.name, got, tt.want)
+		}
+		checkItems(t, items, tt.total)
+	}
+}
+
+func TestFitItem(t *testing.T) {
+
+	if _, err := fitItem(item(t, 1, "0.001"), NewAmount(math.MaxInt64, "RUB")); err != ErrOverflow {
+		t.Errorf("fitItem of overflowing unit price = %v; want %v", err, ErrOverflow)
+	}
+
+	items, err := fitItem(item(t, 1, "6.0"), NewAmount(math.MaxInt64, "RUB"))
+	if err != nil || len(items) != 2 {
+		t.Fatalf("fitItem of large share = %s, %v", itemsString(items), err)
+	}
+	checkItems(t, items, math.MaxInt64)
+}
+
+func TestAllocateItemsQuantity(t *testing.T) {
+
+	for _, quantity := range []string{"0", "-1"} {
+		_, err := AllocateItems([]Item{item(t, 100, "1"), item(t, 100, quantity)}, NewAmount(100, "RUB"))
+		verr, ok := err.(*ValidationError)
+		if !ok || verr.Parameter != "items[1].quantity" {
+			t.Errorf("AllocateItems with quantity %s = %v", quantity, err)
+		}
+	}
+}
+
+func TestDiscountItems(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		items    []Item
+		discount int64
+		want     string
+		err      error
+	}{
+		{"proportional", []Item{item(t, 10000, "1"), item(t, 5000, "2")}, 5000, "75.00 x 1, 37.50 x 2", nil},
+		{"none", []Item{item(t, 10000, "1")}, 0, "100.00 x 1", nil},
+		{"kept small item", []Item{item(t, 100000, "1"), item(t, 1, "3")}, 99900, "1.00 x 1, 0.01 x 3", nil},
+		{"too large", []Item{item(t, 100000, "1"), item(t, 1, "3")}, 100000, "", ErrAllocationTooSmall},
+		{"exceeds", []Item{item(t, 10000, "1")}, 10001, "", ErrAllocationExceedsTotal},
+		{"negative", []Item{item(t, 10000, "1")}, -1, "", ErrAllocationExceedsTotal},
+	}
+
+	for _, tt := range tests {
+		items, err := DiscountItems(tt.items, NewAmount(tt.discount, "RUB"))
+		if err != tt.err {
+			t.Errorf("%s: DiscountItems = %s, %v; want %v", tt.name, itemsString(items), err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := itemsString(items); got != tt.want {
+			t.Errorf("%s: DiscountItems = %s; want %s", tt.name, got, tt.want)
+		}
+		total, _ := itemsTotal(tt.items, "RUB")
+		checkItems(t, items, total.Minor-tt.discount)
+	}
+}
+
+func TestRefundReceipt(t *testing.T) {
+
+	receipt := &Receipt{
+		ID:            "r1",
+		Customer:      &Customer{Email: "user@example.com"},
+		TaxSystemCode: 1,
+		Items:         []Item{item(t, 10000, "1"), item(t, 3333, "3")},
+	}
+
+	refund, err := receipt.RefundReceipt(NewAmount(5000, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkItems(t, refund.Items, 5000)
+
+	if refund.ID != "" || refund.TaxSystemCode != 1 || refund.Customer == nil || refund.Customer == receipt.Customer || refund.Customer.Email != "user@example.com" {
+		t.Errorf("RefundReceipt = %+v", refund)
+	}
+
+	if receipt.Items[0].Amount.Minor != 10000 || receipt.Items[1].Amount.Minor != 3333 {
+		t.Errorf("RefundReceipt changed receipt items: %s", itemsString(receipt.Items))
+	}
+
+	if _, err = receipt.RefundReceipt(NewAmount(100000, "RUB")); err != ErrAllocationExceedsTotal {
+		t.Errorf("RefundReceipt over total = %v; want %v", err, ErrAllocationExceedsTotal)
+	}
+
+	if _, err = receipt.RefundReceipt(NewAmount(5000, "USD")); err != ErrCurrencyMismatch {
+		t.Errorf("RefundReceipt in USD = %v; want %v", err, ErrCurrencyMismatch)
+	}
+
+	discounted, err := receipt.Discounted(NewAmount(1000, "RUB"))
+	if err != nil || len(discounted.Items) < 2 {
+		t.Fatalf("Discounted = %+v, %v", discounted, err)
+	}
+
+	checkItems(t, discounted.Items, 10000+3*3333-1000)
+}
@@ -0,0 +1,217 @@
+package yacheckout
+
+import (
+	"sort"
+	"strconv"
+)
+
+//Limits of payment parameters.See https://kassa.yandex.ru/developers/api#create_payment
+const (
+	MaxDescriptionLength   = 128
+	MaxMetadataKeys        = 16
+	MaxMetadataKeyLength   = 32
+	MaxMetadataValueLength = 512
+)
+
+//holdMethods is payment methods supporting two-stage payments with capture false
+var holdMethods = map[string]bool{
+	BankCard:    true,
+	YandexMoney: true,
+	Sberbank:    true,
+	ApplePay:    true,
+	GooglePay:   true,
+	TinkoffBank: true,
+}
+
+//PaymentBuilder struct assembles payment creation request, see Build
+type PaymentBuilder struct {
+	payment  Payment
+	metadata map[string]string
+}
+
+//NewPaymentBuilder func return PaymentBuilder of payment of amount
+func NewPaymentBuilder(amount Amount) *PaymentBuilder {
+	return &PaymentBuilder{payment: Payment{Amount: &amount}}
+}
+
+//Description func sets description, up to MaxDescriptionLength characters
+func (builder *PaymentBuilder) Description(description string) *PaymentBuilder {
+	builder.payment.Description = description
+	return builder
+}
+
+//Confirmation func sets confirmation scenario such as NewRedirectConfirmation
+func (builder *PaymentBuilder) Confirmation(confirmation *Confirmation) *PaymentBuilder {
+	builder.payment.Confirmation = confirmation
+	return builder
+}
+
+//Receipt func sets receipt, its items must sum to amount
+func (builder *PaymentBuilder) Receipt(receipt *Receipt) *PaymentBuilder {
+	builder.payment.Receipt = receipt
+	return builder
+}
+
+//Metadata func adds metadata key
+func (builder *PaymentBuilder) Metadata(key, value string) *PaymentBuilder {
+
+	if builder.metadata == nil {
+		builder.metadata = map[string]string{}
+	}
+
+	builder.metadata[key] = value
+	return builder
+}
+
+//Transfer func adds transfer of split payment, transfers must sum to amount
+func (builder *PaymentBuilder) Transfer(transfer Transfer) *PaymentBuilder {
+	builder.payment.Transfers = append(builder.payment.Transfers, transfer)
+	return builder
+}
+
+//Capture func sets capture, false holds funds until CapturePayment
+func (builder *PaymentBuilder) Capture(capture bool) *PaymentBuilder {
+	builder.payment.Capture = capture
+	return builder
+}
+
+//PaymentMethodData func sets payment method data
+func (builder *PaymentBuilder) PaymentMethodData(data PaymentMethodData) *PaymentBuilder {
+	builder.payment.PaymentMethodData = data
+	return builder
+}
+
+//PaymentMethodID func sets saved payment method to charge
+func (builder *PaymentBuilder) PaymentMethodID(id string) *PaymentBuilder {
+	builder.payment.PaymentMethodID = id
+	return builder
+}
+
+//PaymentToken func sets one-time token of Checkout.js or mobile SDK
+func (builder *PaymentBuilder) PaymentToken(token string) *PaymentBuilder {
+	builder.payment.PaymentToken = token
+	return builder
+}
+
+//SavePaymentMethod func sets saving of payment method for recurring payments
+func (builder *PaymentBuilder) SavePaymentMethod(save bool) *PaymentBuilder {
+	builder.payment.SavePaymentMethod = save
+	return builder
+}
+
+//ClientIP func sets payer IP address
+func (builder *PaymentBuilder) ClientIP(ip string) *PaymentBuilder {
+	builder.payment.ClientIP = ip
+	return builder
+}
+
+//Build func return payment or ValidationErrors with every invalid parameter
+func (builder *PaymentBuilder) Build() (*Payment, error) {
+
+	payment := builder.payment
+	if builder.metadata != nil {
+		metadata := make(map[string]string, len(builder.metadata))
+		for k, v := range builder.metadata {
+			metadata[k] = v
+		}
+		payment.Metadata = metadata
+	}
+	payment.Transfers = append([]Transfer(nil), payment.Transfers...)
+
+	if err := payment.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+//Validate func checks payment creation request, it return ValidationErrors
+func (payment *Payment) Validate() error {
+
+	var errs ValidationErrors
+
+	switch {
+	case payment.Amount == nil:
+		errs.Add(&ValidationError{Parameter: "amount", Description: "required"})
+	case payment.Amount.Sign() <= 0:
+		errs.Add(&ValidationError{Parameter: "amount.value", Description: "must be positive"})
+	case payment.Amount.Currency == "":
+		errs.Add(&ValidationError{Parameter: "amount.currency", Description: "required"})
+	}
+
+	if n := len([]rune(payment.Description)); n > MaxDescriptionLength {
+		errs.Add(&ValidationError{Parameter: "description", Description: "longer than " + strconv.Itoa(MaxDescriptionLength) + " characters"})
+	}
+
+	if payment.Confirmation != nil {
+		errs.Add(payment.Confirmation.Validate())
+	}
+
+	sources := 0
+	for _, set := range []bool{payment.PaymentToken != "", payment.PaymentMethodID != "", payment.PaymentMethodData != nil} {
+		if set {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		errs.Add(&ValidationError{Parameter: "payment_method_id", Description: "only one of payment_token, payment_method_id and payment_method_data is allowed"})
+	}
+
+	if payment.PaymentMethodID != "" && payment.SavePaymentMethod {
+		errs.Add(&ValidationError{Parameter: "save_payment_method", Description: "payment method is already saved"})
+	}
+
+	if payment.PaymentMethodData != nil {
+		errs.Add(payment.PaymentMethodData.Validate())
+		if typ := payment.PaymentMethodData.PaymentMethodType(); !payment.Capture && !holdMethods[typ] {
+			errs.Add(&ValidationError{Parameter: "capture", Description: typ + " doesn't support two-stage payments"})
+		}
+	}
+
+	if len(payment.Transfers) > 0 && payment.Amount != nil {
+		errs.Add(ValidateTransfers(payment.Transfers, *payment.Amount))
+	}
+
+	if payment.Receipt != nil {
+		errs.Add(payment.Receipt.validate("receipt.", false))
+		if payment.Amount != nil {
+			errs.Add(payment.Receipt.validateTotal("receipt.", *payment.Amount))
+		}
+	}
+
+	errs.Add(validateMetadata(payment.Metadata))
+	return errs.Err()
+}
+
+//validateMetadata func checks limits of metadata given as map[string]string
+func validateMetadata(metadata interface{}) error {
+
+	m, ok := metadata.(map[string]string)
+	if !ok {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if len(m) > MaxMetadataKeys {
+		errs.Add(&ValidationError{Parameter: "metadata", Description: "more than " + strconv.Itoa(MaxMetadataKeys) + " keys"})
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := m[k]
+		if len([]rune(k)) > MaxMetadataKeyLength {
+			errs.Add(&ValidationError{Parameter: "metadata." + k, Description: "key longer than " + strconv.Itoa(MaxMetadataKeyLength) + " characters"})
+		}
+		if len([]rune(v)) > MaxMetadataValueLength {
+			errs.Add(&ValidationError{Parameter: "metadata." + k, Description: "value longer than " + strconv.Itoa(MaxMetadataValueLength) + " characters"})
+		}
+	}
+
+	return errs.Err()
+}
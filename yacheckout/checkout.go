@@ -2,18 +2,20 @@ package yacheckout
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 //APIEndpoints
 const (
-	APIEndpoint      = "https://payment.yandex.net/api/v3/"
+	APIEndpoint = "https://payment.yandex.net/api/v3/"
 )
 
 //Payment methods.See https://kassa.yandex.ru/developers/payment-methods/overview
@@ -57,8 +59,11 @@ const (
 const (
 	DSecureFailed              = "3d_secure_failed"
 	CallIssuer                 = "call_issuer"
+	CanceledByMerchant         = "canceled_by_merchant"
 	CardExpired                = "card_expired"
 	CountryForbidden           = "country_forbidden"
+	ExpiredOnCapture           = "expired_on_capture"
+	ExpiredOnConfirmation      = "expired_on_confirmation"
 	FraudSuspected             = "fraud_suspected"
 	GeneralDecline             = "general_decline"
 	IdentificationRequired     = "identification_required"
@@ -77,14 +82,36 @@ const (
 	PaymentSucceeded         = "payment.succeeded"
 	PaymentCanceled          = "payment.canceled"
 	RefundSucceeded          = "refund.succeeded"
+	PayoutSucceeded          = "payout.succeeded"
+	PayoutCanceled           = "payout.canceled"
+	DealClosed               = "deal.closed"
 )
 
-//Payment Statuses.See https://kassa.yandex.ru/developers/payments/basics/payment-process#payment-statuses
+//PaymentStatus is payment.status.See https://kassa.yandex.ru/developers/payments/basics/payment-process#payment-statuses
+type PaymentStatus string
+
+//Payment Statuses
 const (
-	Pending           = "pending"
-	WaitingForCapture = "waiting_for_capture"
-	Succeeded         = "succeeded"
-	Canceled          = "canceled"
+	Pending           PaymentStatus = "pending"
+	WaitingForCapture PaymentStatus = "waiting_for_capture"
+	Succeeded         PaymentStatus = "succeeded"
+	Canceled          PaymentStatus = "canceled"
+)
+
+//Deal statuses.See https://kassa.yandex.ru/developers/api#deal_object_status
+const (
+	Opened = "opened"
+	Closed = "closed"
+)
+
+//PayoutStatus is payout.status.See https://kassa.yandex.ru/developers/api#payout_object_status
+type PayoutStatus string
+
+//Payout statuses
+const (
+	PayoutStatusPending   PayoutStatus = "pending"
+	PayoutStatusSucceeded PayoutStatus = "succeeded"
+	PayoutStatusCanceled  PayoutStatus = "canceled"
 )
 
 //Tax systems.See https://kassa.yandex.ru/developers/payments/54fz/parameters-values#tax-systems
@@ -153,11 +180,24 @@ type Checkout struct {
 	ShopID        int
 	SecurityToken string
 	OAuthToken    string
+	Retry         *RetryPolicy
+	Endpoint      string         //base URL of API, APIEndpoint when empty
+	HTTPClient    *http.Client   //client used when nil is passed to operations
+	Timeout       time.Duration  //limit of a single attempt, no limit when zero
+	UserAgent     string         //User-Agent header, Go default when empty
+	Header        http.Header    //extra headers sent with every request
+	States        *PaymentStates //last known payment states checked before capture, cancel and refund, nil disables
 }
 
-//NewCheckout func return Checkout struct
-func NewCheckout(id int, stoken, oatoken string) *Checkout {
-	return &Checkout{ShopID: id, SecurityToken: stoken, OAuthToken: oatoken}
+//NewCheckout func return Checkout struct configured by opts
+func NewCheckout(id int, stoken, oatoken string, opts ...Option) *Checkout {
+
+	checkout := &Checkout{ShopID: id, SecurityToken: stoken, OAuthToken: oatoken, States: NewPaymentStates(DefaultPaymentStatesSize)}
+	for _, opt := range opts {
+		opt(checkout)
+	}
+
+	return checkout
 }
 
 //Exec func is custom execution
@@ -165,18 +205,50 @@ func NewCheckout(id int, stoken, oatoken string) *Checkout {
 //https://kassa.yandex.ru/developers/using-api/basics#idempotence
 //Use https://godoc.org/github.com/google/uuid#NewRandom
 func (checkout *Checkout) Exec(endpoint string, client *http.Client, httpMethod string, V4UUID *uuid.UUID, method string, data []byte) (b []byte, apierr *Error, err error) {
+	return checkout.ExecContext(context.Background(), endpoint, client, httpMethod, V4UUID, method, data)
+}
+
+//ExecContext func is custom execution bound to ctx.
+//Cancellation or deadline of ctx aborts the request in flight.
+//Transient failures are retried according to checkout.Retry with the same V4UUID.
+//Empty endpoint and nil client are taken from checkout
+func (checkout *Checkout) ExecContext(ctx context.Context, endpoint string, client *http.Client, httpMethod string, V4UUID *uuid.UUID, method string, data []byte) (b []byte, apierr *Error, err error) {
+
+	if endpoint == "" {
+		endpoint = checkout.Endpoint
+	}
+	if endpoint == "" {
+		endpoint = APIEndpoint
+	}
+
+	if client == nil {
+		client = checkout.HTTPClient
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
 
 	var req *http.Request
 
 	switch httpMethod {
 	case http.MethodGet:
-		req, err = http.NewRequest(http.MethodGet, endpoint+method, nil)
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint+method, nil)
 	case http.MethodPost:
-		req, err = http.NewRequest(http.MethodPost, endpoint+method, bytes.NewBuffer(data))
+		if V4UUID == nil {
+			key := uuid.New()
+			V4UUID = &key
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint+method, bytes.NewReader(data))
+		if err != nil {
+			return
+		}
 		req.Header.Set("Idempotence-Key", V4UUID.String())
 		req.Header.Set("Content-Type", "application/json")
 	case http.MethodDelete:
-		req, err = http.NewRequest(http.MethodDelete, endpoint+method, nil)
+		req, err = http.NewRequestWithContext(ctx, http.MethodDelete, endpoint+method, nil)
+		if err != nil {
+			return
+		}
 		req.Header.Set("Content-Type", "application/json")
 	default:
 		err = errors.New("Unknown HTTP method")
@@ -187,12 +259,63 @@ func (checkout *Checkout) Exec(endpoint string, client *http.Client, httpMethod
 		return
 	}
 
+	for key, values := range checkout.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	if checkout.UserAgent != "" {
+		req.Header.Set("User-Agent", checkout.UserAgent)
+	}
+
 	if checkout.OAuthToken == "" {
 		req.SetBasicAuth(strconv.Itoa(checkout.ShopID), checkout.SecurityToken)
 	} else {
 		req.Header.Set("Authorization", "Bearer "+checkout.OAuthToken)
 	}
 
+	for attempt := 1; ; attempt++ {
+		var status int
+
+		b, status, apierr, err = checkout.send(client, req)
+		if apierr != nil {
+			apierr.StatusCode = status
+			if V4UUID != nil {
+				apierr.IdempotenceKey = V4UUID.String()
+			}
+		}
+
+		if !checkout.Retry.retry(ctx, attempt, status, err) {
+			return
+		}
+
+		if err = checkout.Retry.wait(ctx, attempt, apierr); err != nil {
+			b, apierr = nil, nil
+			return
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+//exec func is ExecContext with endpoint of checkout
+func (checkout *Checkout) exec(ctx context.Context, client *http.Client, httpMethod string, V4UUID *uuid.UUID, method string, data []byte) ([]byte, *Error, error) {
+	return checkout.ExecContext(ctx, "", client, httpMethod, V4UUID, method, data)
+}
+
+//send func performs a single attempt of ExecContext limited by checkout.Timeout.
+//Response other than 200 is returned as apierr, body which isn't error object is replaced by statusError
+func (checkout *Checkout) send(client *http.Client, req *http.Request) (b []byte, status int, apierr *Error, err error) {
+
+	if checkout.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), checkout.Timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
 	res, err := client.Do(req)
 	if err != nil {
 		return
@@ -204,8 +327,14 @@ func (checkout *Checkout) Exec(endpoint string, client *http.Client, httpMethod
 		return
 	}
 
-	if res.StatusCode != http.StatusOK {
-		err = json.Unmarshal(b, &apierr)
+	status = res.StatusCode
+	if status != http.StatusOK {
+		if json.Unmarshal(b, &apierr) != nil || apierr == nil {
+			apierr = statusError(status)
+		}
+		if apierr.RetryAfter == 0 {
+			apierr.RetryAfter = retryAfter(res.Header, time.Now())
+		}
 	}
 
 	return
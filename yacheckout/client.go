@@ -0,0 +1,189 @@
+package yacheckout
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+//Client struct is Checkout with (result, error) API.
+//Response errors are returned as *Error, use errors.Is with ErrNotFound etc. or errors.As
+type Client struct {
+	Checkout *Checkout
+}
+
+//NewClient func return Client struct
+func NewClient(checkout *Checkout) *Client {
+	return &Client{Checkout: checkout}
+}
+
+//Exec func is custom execution
+func (c *Client) Exec(ctx context.Context, endpoint string, client *http.Client, httpMethod string, V4UUID *uuid.UUID, method string, data []byte) ([]byte, error) {
+	b, apierr, err := c.Checkout.ExecContext(ctx, endpoint, client, httpMethod, V4UUID, method, data)
+	return b, joinError(apierr, err)
+}
+
+//CreatePayment func create payment Yandex.Checkout
+func (c *Client) CreatePayment(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, pay *Payment) (*Payment, error) {
+	payment, apierr, err := c.Checkout.CreatePaymentContext(ctx, client, V4UUID, pay)
+	return payment, joinError(apierr, err)
+}
+
+//GetPayment func receives payment information Yandex.Checkout
+func (c *Client) GetPayment(ctx context.Context, client *http.Client, id string) (*Payment, error) {
+	payment, apierr, err := c.Checkout.GetPaymentContext(ctx, client, id)
+	return payment, joinError(apierr, err)
+}
+
+//CapturePayment func confirm payment Yandex.Checkout
+func (c *Client) CapturePayment(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, id string, pay *Payment) (*Payment, error) {
+	payment, apierr, err := c.Checkout.CapturePaymentContext(ctx, client, V4UUID, id, pay)
+	return payment, joinError(apierr, err)
+}
+
+//CancelPayment func cancel payment Yandex.Checkout
+func (c *Client) CancelPayment(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, id string) (*Payment, error) {
+	payment, apierr, err := c.Checkout.CancelPaymentContext(ctx, client, V4UUID, id)
+	return payment, joinError(apierr, err)
+}
+
+//ListPayments func receives payments list Yandex.Checkout
+func (c *Client) ListPayments(ctx context.Context, client *http.Client, filter *PaymentsFilter) (*Payments, error) {
+	payments, apierr, err := c.Checkout.ListPaymentsContext(ctx, client, filter)
+	return payments, joinError(apierr, err)
+}
+
+//IteratePayments func return iterator over all payments matching filter
+func (c *Client) IteratePayments(client *http.Client, filter *PaymentsFilter) *PaymentIterator {
+	return c.Checkout.IteratePayments(client, filter)
+}
+
+//WaitForPayment func polls payment until it reaches awaited status, see Checkout.WaitForPaymentContext
+func (c *Client) WaitForPayment(ctx context.Context, client *http.Client, id string, opts *WaitOptions) (*Payment, error) {
+	payment, apierr, err := c.Checkout.WaitForPaymentContext(ctx, client, id, opts)
+	return payment, joinError(apierr, err)
+}
+
+//ChargeSavedMethod func charges saved payment method methodID Yandex.Checkout.
+//Result is returned with *Error when methodID is rejected
+func (c *Client) ChargeSavedMethod(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, methodID string, pay *Payment) (*ChargeResult, error) {
+	result, apierr, err := c.Checkout.ChargeSavedMethodContext(ctx, client, V4UUID, methodID, pay)
+	return result, joinError(apierr, err)
+}
+
+//CreateRefund func create refund Yandex.Checkout
+func (c *Client) CreateRefund(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, rfd *Refund) (*Refund, error) {
+	refund, apierr, err := c.Checkout.CreateRefundContext(ctx, client, V4UUID, rfd)
+	return refund, joinError(apierr, err)
+}
+
+//GetRefund func receives refund information Yandex.Checkout
+func (c *Client) GetRefund(ctx context.Context, client *http.Client, id string) (*Refund, error) {
+	refund, apierr, err := c.Checkout.GetRefundContext(ctx, client, id)
+	return refund, joinError(apierr, err)
+}
+
+//ListRefunds func receives refunds list Yandex.Checkout
+func (c *Client) ListRefunds(ctx context.Context, client *http.Client, filter *RefundsFilter) (*Refunds, error) {
+	refunds, apierr, err := c.Checkout.ListRefundsContext(ctx, client, filter)
+	return refunds, joinError(apierr, err)
+}
+
+//IterateRefunds func return iterator over all refunds matching filter
+func (c *Client) IterateRefunds(client *http.Client, filter *RefundsFilter) *RefundIterator {
+	return c.Checkout.IterateRefunds(client, filter)
+}
+
+//CreateReceipt func create receipt Yandex.Checkout
+func (c *Client) CreateReceipt(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, rcpt *Receipt) (*Receipt, error) {
+	receipt, apierr, err := c.Checkout.CreateReceiptContext(ctx, client, V4UUID, rcpt)
+	return receipt, joinError(apierr, err)
+}
+
+//GetReceipts func receives receipts Yandex.Checkout
+func (c *Client) GetReceipts(ctx context.Context, client *http.Client, refund bool, id string) (*Receipts, error) {
+	receipts, apierr, err := c.Checkout.GetReceiptsContext(ctx, client, refund, id)
+	return receipts, joinError(apierr, err)
+}
+
+//ListReceipts func receives receipts list Yandex.Checkout
+func (c *Client) ListReceipts(ctx context.Context, client *http.Client, filter *ReceiptsFilter) (*Receipts, error) {
+	receipts, apierr, err := c.Checkout.ListReceiptsContext(ctx, client, filter)
+	return receipts, joinError(apierr, err)
+}
+
+//IterateReceipts func return iterator over all receipts matching filter
+func (c *Client) IterateReceipts(client *http.Client, filter *ReceiptsFilter) *ReceiptIterator {
+	return c.Checkout.IterateReceipts(client, filter)
+}
+
+//GetReceipt func receives receipt Yandex.Checkout
+func (c *Client) GetReceipt(ctx context.Context, client *http.Client, id string) (*Receipt, error) {
+	receipt, apierr, err := c.Checkout.GetReceiptContext(ctx, client, id)
+	return receipt, joinError(apierr, err)
+}
+
+//CreateWebhook func create webhook Yandex.Checkout
+func (c *Client) CreateWebhook(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, webhk *Webhook) (*Webhook, error) {
+	webhook, apierr, err := c.Checkout.CreateWebhookContext(ctx, client, V4UUID, webhk)
+	return webhook, joinError(apierr, err)
+}
+
+//GetWebhooks func receives webhooks Yandex.Checkout
+func (c *Client) GetWebhooks(ctx context.Context, client *http.Client) (*Webhooks, error) {
+	webhook, apierr, err := c.Checkout.GetWebhooksContext(ctx, client)
+	return webhook, joinError(apierr, err)
+}
+
+//DeleteWebhook func delete webhook Yandex.Checkout
+func (c *Client) DeleteWebhook(ctx context.Context, client *http.Client, id string) error {
+	return joinError(c.Checkout.DeleteWebhookContext(ctx, client, id))
+}
+
+//SyncWebhooks func makes webhooks Yandex.Checkout equal to desired ones
+func (c *Client) SyncWebhooks(ctx context.Context, client *http.Client, desired []Webhook, dryRun bool) (*WebhooksPlan, error) {
+	plan, apierr, err := c.Checkout.SyncWebhooksContext(ctx, client, desired, dryRun)
+	return plan, joinError(apierr, err)
+}
+
+//CreatePayout func create payout Yandex.Checkout
+func (c *Client) CreatePayout(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, pout *Payout) (*Payout, error) {
+	payout, apierr, err := c.Checkout.CreatePayoutContext(ctx, client, V4UUID, pout)
+	return payout, joinError(apierr, err)
+}
+
+//GetPayout func receives payout information Yandex.Checkout
+func (c *Client) GetPayout(ctx context.Context, client *http.Client, id string) (*Payout, error) {
+	payout, apierr, err := c.Checkout.GetPayoutContext(ctx, client, id)
+	return payout, joinError(apierr, err)
+}
+
+//CreateDeal func create deal Yandex.Checkout
+func (c *Client) CreateDeal(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, dl *Deal) (*Deal, error) {
+	deal, apierr, err := c.Checkout.CreateDealContext(ctx, client, V4UUID, dl)
+	return deal, joinError(apierr, err)
+}
+
+//GetDeal func receives deal information Yandex.Checkout
+func (c *Client) GetDeal(ctx context.Context, client *http.Client, id string) (*Deal, error) {
+	deal, apierr, err := c.Checkout.GetDealContext(ctx, client, id)
+	return deal, joinError(apierr, err)
+}
+
+//ListDeals func receives deals list Yandex.Checkout
+func (c *Client) ListDeals(ctx context.Context, client *http.Client, filter *DealsFilter) (*Deals, error) {
+	deals, apierr, err := c.Checkout.ListDealsContext(ctx, client, filter)
+	return deals, joinError(apierr, err)
+}
+
+//IterateDeals func return iterator over all deals matching filter
+func (c *Client) IterateDeals(client *http.Client, filter *DealsFilter) *DealIterator {
+	return c.Checkout.IterateDeals(client, filter)
+}
+
+//GetMe func receives me information Yandex.Checkout
+func (c *Client) GetMe(ctx context.Context, client *http.Client) (*Me, error) {
+	me, apierr, err := c.Checkout.GetMeContext(ctx, client)
+	return me, joinError(apierr, err)
+}
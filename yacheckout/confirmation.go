@@ -0,0 +1,144 @@
+package yacheckout
+
+//Confirmation types.See https://kassa.yandex.ru/developers/payments/payment-process#user-confirmation
+const (
+	Redirect          = "redirect"
+	Embedded          = "embedded"
+	External          = "external"
+	QR                = "qr"
+	MobileApplication = "mobile_application"
+)
+
+//ConfirmationScenario is typed variant of Confirmation, see Confirmation.Scenario
+type ConfirmationScenario interface {
+	Confirmation() *Confirmation
+}
+
+//RedirectConfirmation struct is redirect confirmation, payer follows ConfirmationURL
+type RedirectConfirmation struct {
+	ConfirmationURL string
+	ReturnURL       string
+	Enforce         bool
+	Locale          string
+}
+
+//EmbeddedConfirmation struct is confirmation in Checkout widget initialized with ConfirmationToken
+type EmbeddedConfirmation struct {
+	ConfirmationToken string
+	Locale            string
+}
+
+//ExternalConfirmation struct is confirmation outside Yandex.Checkout such as SMS
+type ExternalConfirmation struct {
+	Locale string
+}
+
+//QRConfirmation struct is confirmation by scanning QR code of ConfirmationData
+type QRConfirmation struct {
+	ConfirmationData string
+	Locale           string
+}
+
+//MobileApplicationConfirmation struct is confirmation in mobile application of payment method
+type MobileApplicationConfirmation struct {
+	ConfirmationURL string
+	ReturnURL       string
+	Locale          string
+}
+
+//NewRedirectConfirmation func return redirect confirmation request
+func NewRedirectConfirmation(returnURL string, enforce bool) *Confirmation {
+	return (&RedirectConfirmation{ReturnURL: returnURL, Enforce: enforce}).Confirmation()
+}
+
+//NewEmbeddedConfirmation func return embedded confirmation request
+func NewEmbeddedConfirmation() *Confirmation {
+	return (&EmbeddedConfirmation{}).Confirmation()
+}
+
+//NewExternalConfirmation func return external confirmation request
+func NewExternalConfirmation() *Confirmation {
+	return (&ExternalConfirmation{}).Confirmation()
+}
+
+//NewQRConfirmation func return QR code confirmation request
+func NewQRConfirmation() *Confirmation {
+	return (&QRConfirmation{}).Confirmation()
+}
+
+//NewMobileApplicationConfirmation func return mobile application confirmation request
+func NewMobileApplicationConfirmation(returnURL string) *Confirmation {
+	return (&MobileApplicationConfirmation{ReturnURL: returnURL}).Confirmation()
+}
+
+//Confirmation func implements ConfirmationScenario
+func (c *RedirectConfirmation) Confirmation() *Confirmation {
+	return &Confirmation{Type: Redirect, ConfirmationURL: c.ConfirmationURL, ReturnURL: c.ReturnURL, Enforce: c.Enforce, Locale: c.Locale}
+}
+
+//Confirmation func implements ConfirmationScenario
+func (c *EmbeddedConfirmation) Confirmation() *Confirmation {
+	return &Confirmation{Type: Embedded, ConfirmationToken: c.ConfirmationToken, Locale: c.Locale}
+}
+
+//Confirmation func implements ConfirmationScenario
+func (c *ExternalConfirmation) Confirmation() *Confirmation {
+	return &Confirmation{Type: External, Locale: c.Locale}
+}
+
+//Confirmation func implements ConfirmationScenario
+func (c *QRConfirmation) Confirmation() *Confirmation {
+	return &Confirmation{Type: QR, ConfirmationData: c.ConfirmationData, Locale: c.Locale}
+}
+
+//Confirmation func implements ConfirmationScenario
+func (c *MobileApplicationConfirmation) Confirmation() *Confirmation {
+	return &Confirmation{Type: MobileApplication, ConfirmationURL: c.ConfirmationURL, ReturnURL: c.ReturnURL, Locale: c.Locale}
+}
+
+//Scenario func return typed variant of confirmation by Type
+func (confirmation *Confirmation) Scenario() (ConfirmationScenario, error) {
+
+	c := confirmation
+	switch c.Type {
+	case Redirect:
+		return &RedirectConfirmation{ConfirmationURL: c.ConfirmationURL, ReturnURL: c.ReturnURL, Enforce: c.Enforce, Locale: c.Locale}, nil
+	case Embedded:
+		return &EmbeddedConfirmation{ConfirmationToken: c.ConfirmationToken, Locale: c.Locale}, nil
+	case External:
+		return &ExternalConfirmation{Locale: c.Locale}, nil
+	case QR:
+		return &QRConfirmation{ConfirmationData: c.ConfirmationData, Locale: c.Locale}, nil
+	case MobileApplication:
+		return &MobileApplicationConfirmation{ConfirmationURL: c.ConfirmationURL, ReturnURL: c.ReturnURL, Locale: c.Locale}, nil
+	}
+
+	return nil, &ValidationError{Parameter: "confirmation.type", Description: "unknown confirmation type " + c.Type}
+}
+
+//Validate func checks confirmation of payment creation request.
+//Redirect and mobile_application need ReturnURL, fields set by API must be empty
+func (confirmation *Confirmation) Validate() error {
+
+	if _, err := confirmation.Scenario(); err != nil {
+		return err
+	}
+
+	c := confirmation
+	switch {
+	case (c.Type == Redirect || c.Type == MobileApplication) && c.ReturnURL == "":
+		return &ValidationError{Parameter: "confirmation.return_url", Description: "required for " + c.Type + " confirmation"}
+	case c.Type != Redirect && c.Type != MobileApplication && c.ReturnURL != "":
+		return &ValidationError{Parameter: "confirmation.return_url", Description: "not supported by " + c.Type + " confirmation"}
+	case c.Type != Redirect && c.Enforce:
+		return &ValidationError{Parameter: "confirmation.enforce", Description: "not supported by " + c.Type + " confirmation"}
+	case c.ConfirmationURL != "":
+		return &ValidationError{Parameter: "confirmation.confirmation_url", Description: "set by Yandex.Checkout"}
+	case c.ConfirmationToken != "":
+		return &ValidationError{Parameter: "confirmation.confirmation_token", Description: "set by Yandex.Checkout"}
+	case c.ConfirmationData != "":
+		return &ValidationError{Parameter: "confirmation.confirmation_data", Description: "set by Yandex.Checkout"}
+	}
+
+	return nil
+}
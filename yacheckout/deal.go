@@ -0,0 +1,209 @@
+package yacheckout
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//Deal types.See https://kassa.yandex.ru/developers/api#deal_object
+const (
+	SafeDeal = "safe_deal"
+)
+
+//Deal fee moments.See https://kassa.yandex.ru/developers/api#deal_object_fee_moment
+const (
+	FeeMomentPaymentSucceeded = "payment_succeeded"
+	FeeMomentDealClosed       = "deal_closed"
+)
+
+//Deal settlement types.See https://kassa.yandex.ru/developers/api#payment_object_deal_settlements
+const (
+	PayoutSettlement = "payout"
+)
+
+//Deal struct is Yandex.Checkout deal object of Safe Deal.
+//Balance is amount of deal, PayoutBalance is amount available for payouts to seller
+type Deal struct {
+	Type          string      `json:"type"`
+	ID            string      `json:"id,omitempty"`
+	FeeMoment     string      `json:"fee_moment"`
+	Description   string      `json:"description,omitempty"`
+	Balance       *Amount     `json:"balance,omitempty"`
+	PayoutBalance *Amount     `json:"payout_balance,omitempty"`
+	Status        string      `json:"status,omitempty"`
+	CreatedAt     *time.Time  `json:"created_at,string,omitempty"`
+	ExpiresAt     *time.Time  `json:"expires_at,string,omitempty"`
+	Metadata      interface{} `json:"metadata,omitempty"`
+	Test          bool        `json:"test,omitempty"`
+}
+
+//DealInfo struct is deal object of payment, refund and payout.
+//Settlements are set on payments, RefundSettlements on refunds, payouts have only ID
+type DealInfo struct {
+	ID                string           `json:"id"`
+	Settlements       []DealSettlement `json:"settlements,omitempty"`
+	RefundSettlements []DealSettlement `json:"refund_settlements,omitempty"`
+}
+
+//DealSettlement struct is deal.settlements object, amount for payout to seller
+type DealSettlement struct {
+	Type   string  `json:"type"`
+	Amount *Amount `json:"amount"`
+}
+
+//NewDeal func return safe deal with fee withheld at feeMoment
+func NewDeal(feeMoment, description string) *Deal {
+	return &Deal{Type: SafeDeal, FeeMoment: feeMoment, Description: description}
+}
+
+//NewDealInfo func return deal info of payment or refund paying amount out to seller
+func NewDealInfo(id string, payout Amount) *DealInfo {
+	return &DealInfo{ID: id, Settlements: []DealSettlement{{Type: PayoutSettlement, Amount: &payout}}}
+}
+
+//Deals struct is Yandex.Checkout deals list object
+type Deals struct {
+	Type       string `json:"type"`
+	Items      []Deal `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+//DealsFilter struct is deals list filter.See https://kassa.yandex.ru/developers/api#get_deals_list
+type DealsFilter struct {
+	CreatedAt      TimeRange
+	ExpiresAt      TimeRange
+	Status         string
+	FullTextSearch string
+	Limit          int
+	Cursor         string
+}
+
+//Query func return filter as URL query
+func (filter *DealsFilter) Query() url.Values {
+
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+
+	filter.CreatedAt.encode(query, "created_at")
+	filter.ExpiresAt.encode(query, "expires_at")
+
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+
+	if filter.FullTextSearch != "" {
+		query.Set("full_text_search", filter.FullTextSearch)
+	}
+
+	encodeList(query, filter.Limit, filter.Cursor)
+	return query
+}
+
+//CreateDeal func create deal Yandex.Checkout
+func (checkout *Checkout) CreateDeal(client *http.Client, V4UUID *uuid.UUID, dl *Deal) (deal *Deal, apierr *Error, err error) {
+	return checkout.CreateDealContext(context.Background(), client, V4UUID, dl)
+}
+
+//CreateDealContext func create deal Yandex.Checkout bound to ctx
+func (checkout *Checkout) CreateDealContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, dl *Deal) (deal *Deal, apierr *Error, err error) {
+
+	b, err := json.Marshal(dl)
+	if err != nil {
+		return
+	}
+
+	b, apierr, err = checkout.exec(ctx, client, http.MethodPost, V4UUID, "deals", b)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &deal)
+	return
+}
+
+//GetDeal func receives deal information Yandex.Checkout
+func (checkout *Checkout) GetDeal(client *http.Client, id string) (deal *Deal, apierr *Error, err error) {
+	return checkout.GetDealContext(context.Background(), client, id)
+}
+
+//GetDealContext func receives deal information Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetDealContext(ctx context.Context, client *http.Client, id string) (deal *Deal, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, "deals/"+url.PathEscape(id), nil)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &deal)
+	return
+}
+
+//ListDeals func receives deals list Yandex.Checkout
+func (checkout *Checkout) ListDeals(client *http.Client, filter *DealsFilter) (deals *Deals, apierr *Error, err error) {
+	return checkout.ListDealsContext(context.Background(), client, filter)
+}
+
+//ListDealsContext func receives deals list Yandex.Checkout bound to ctx
+func (checkout *Checkout) ListDealsContext(ctx context.Context, client *http.Client, filter *DealsFilter) (deals *Deals, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, withQuery("deals", filter.Query()), nil)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &deals)
+	return
+}
+
+//DealIterator struct iterates deals list following next_cursor
+type DealIterator struct {
+	checkout *Checkout
+	client   *http.Client
+	filter   DealsFilter
+	page     []Deal
+	pager    pager
+}
+
+//IterateDeals func return iterator over all deals matching filter
+func (checkout *Checkout) IterateDeals(client *http.Client, filter *DealsFilter) *DealIterator {
+
+	it := &DealIterator{checkout: checkout, client: client}
+	if filter != nil {
+		it.filter = *filter
+		it.pager.cursor = filter.Cursor
+	}
+
+	return it
+}
+
+//Next func advances iterator, it return false when deals are over or on error
+func (it *DealIterator) Next(ctx context.Context) bool {
+	return it.pager.next(ctx, func(ctx context.Context, cursor string) (int, string, error) {
+
+		it.filter.Cursor = cursor
+		deals, apierr, err := it.checkout.ListDealsContext(ctx, it.client, &it.filter)
+		if err = joinError(apierr, err); err != nil {
+			return 0, "", err
+		}
+
+		it.page = deals.Items
+		return len(it.page), deals.NextCursor, nil
+	})
+}
+
+//Deal func return current deal
+func (it *DealIterator) Deal() *Deal {
+	return &it.page[it.pager.index]
+}
+
+//Err func return error stopped iteration, *Error for response errors
+func (it *DealIterator) Err() error {
+	return it.pager.err
+}
@@ -1,11 +1,114 @@
 package yacheckout
 
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//Error codes.See https://kassa.yandex.ru/developers/using-api/response-handling/response-codes
+const (
+	InvalidRequest      = "invalid_request"
+	InvalidCredentials  = "invalid_credentials"
+	Forbidden           = "forbidden"
+	NotFound            = "not_found"
+	TooManyRequests     = "too_many_requests"
+	InternalServerError = "internal_server_error"
+)
+
+//Sentinel errors matched by Error.Code with errors.Is
+var (
+	ErrInvalidRequest      = &Error{Type: "error", Code: InvalidRequest}
+	ErrInvalidCredentials  = &Error{Type: "error", Code: InvalidCredentials}
+	ErrForbidden           = &Error{Type: "error", Code: Forbidden}
+	ErrNotFound            = &Error{Type: "error", Code: NotFound}
+	ErrTooManyRequests     = &Error{Type: "error", Code: TooManyRequests}
+	ErrInternalServerError = &Error{Type: "error", Code: InternalServerError}
+)
+
 //Error struct is response error Yandex.Checkout
 type Error struct {
-	Type        string `json:"type"`
-	ID          string `json:"id"`
-	Code        string `json:"code"`
-	Description string `json:"description"`
-	Parameter   string `json:"parameter"`
-	RetryAfter  uint32 `json:"retry_after"`
+	Type           string `json:"type"`
+	ID             string `json:"id"`
+	Code           string `json:"code"`
+	Description    string `json:"description"`
+	Parameter      string `json:"parameter"`
+	RetryAfter     uint32 `json:"retry_after"`
+	StatusCode     int    `json:"-"`
+	IdempotenceKey string `json:"-"`
+}
+
+//Error func implements error interface
+func (apierr *Error) Error() string {
+
+	var sb strings.Builder
+
+	sb.WriteString("yacheckout: ")
+	if apierr.StatusCode != 0 {
+		sb.WriteString(strconv.Itoa(apierr.StatusCode))
+		sb.WriteString(" ")
+	}
+
+	if apierr.Code != "" {
+		sb.WriteString(apierr.Code)
+	} else {
+		sb.WriteString(apierr.Type)
+	}
+
+	if apierr.Description != "" {
+		sb.WriteString(": ")
+		sb.WriteString(apierr.Description)
+	}
+
+	if apierr.Parameter != "" {
+		sb.WriteString(" (parameter ")
+		sb.WriteString(apierr.Parameter)
+		sb.WriteString(")")
+	}
+
+	return sb.String()
+}
+
+//Is func reports whether target is *Error with the same Code, for use with errors.Is, nil apierr matches nothing
+func (apierr *Error) Is(target error) bool {
+
+	t, ok := target.(*Error)
+	if apierr == nil || !ok || t.Code == "" {
+		return false
+	}
+
+	return t.Code == apierr.Code
+}
+
+//statusError func return Error of response with status which body isn't error object, such as HTML page of proxy
+func statusError(status int) *Error {
+
+	apierr := &Error{Type: "error", Description: http.StatusText(status)}
+
+	switch {
+	case status == http.StatusBadRequest:
+		apierr.Code = InvalidRequest
+	case status == http.StatusUnauthorized:
+		apierr.Code = InvalidCredentials
+	case status == http.StatusForbidden:
+		apierr.Code = Forbidden
+	case status == http.StatusNotFound:
+		apierr.Code = NotFound
+	case status == http.StatusTooManyRequests:
+		apierr.Code = TooManyRequests
+	case status >= http.StatusInternalServerError:
+		apierr.Code = InternalServerError
+	}
+
+	return apierr
+}
+
+//joinError func return apierr or err as single error
+func joinError(apierr *Error, err error) error {
+
+	if apierr != nil {
+		return apierr
+	}
+
+	return err
 }
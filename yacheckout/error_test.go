@@ -0,0 +1,83 @@
+package yacheckout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+
+	var nilErr *Error
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"same code", &Error{Type: "error", Code: NotFound}, ErrNotFound, true},
+		{"other code", &Error{Type: "error", Code: NotFound}, ErrForbidden, false},
+		{"wrapped", fmt.Errorf("get: %w", &Error{Code: InvalidRequest}), ErrInvalidRequest, true},
+		{"target without code", &Error{Code: NotFound}, &Error{}, false},
+		{"not Error target", &Error{Code: NotFound}, errors.New("not_found"), false},
+		{"nil receiver", nilErr, ErrNotFound, false},
+	}
+
+	for _, tt := range tests {
+		if got := errors.Is(tt.err, tt.target); got != tt.want {
+			t.Errorf("%s: errors.Is = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestErrorString(t *testing.T) {
+
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{Type: "error", Code: NotFound}, "yacheckout: not_found"},
+		{&Error{Type: "processing"}, "yacheckout: processing"},
+		{&Error{Code: InvalidRequest, Description: "Bad amount", Parameter: "amount", StatusCode: 400}, "yacheckout: 400 invalid_request: Bad amount (parameter amount)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q; want %q", got, tt.want)
+		}
+	}
+}
+
+func TestExecStatusError(t *testing.T) {
+
+	tests := []struct {
+		status int
+		body   string
+		target *Error
+	}{
+		{http.StatusBadGateway, "<html><body>502 Bad Gateway</body></html>", ErrInternalServerError},
+		{http.StatusServiceUnavailable, "", ErrInternalServerError},
+		{http.StatusNotFound, "", ErrNotFound},
+		{http.StatusUnauthorized, "Unauthorized", ErrInvalidCredentials},
+		{http.StatusTooManyRequests, "null", ErrTooManyRequests},
+		{http.StatusBadRequest, `{"type":"error","code":"invalid_request","parameter":"amount"}`, ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		_, apierr, err := (&Checkout{}).ExecContext(context.Background(), srv.URL+"/", srv.Client(), http.MethodGet, nil, "payments/1", nil)
+		srv.Close()
+
+		if err != nil || apierr == nil || apierr.StatusCode != tt.status || !errors.Is(apierr, tt.target) {
+			t.Errorf("%d %q: ExecContext = %+v, %v; want %s with status", tt.status, tt.body, apierr, err, tt.target.Code)
+		}
+	}
+}
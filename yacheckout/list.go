@@ -0,0 +1,83 @@
+package yacheckout
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//TimeRange struct is time filter of list requests such as created_at.gte.
+//Nil bounds are not sent
+type TimeRange struct {
+	Gte *time.Time
+	Gt  *time.Time
+	Lte *time.Time
+	Lt  *time.Time
+}
+
+//encode func adds bounds of r to query as name.gte, name.gt, name.lte and name.lt
+func (r TimeRange) encode(query url.Values, name string) {
+
+	for op, t := range map[string]*time.Time{"gte": r.Gte, "gt": r.Gt, "lte": r.Lte, "lt": r.Lt} {
+		if t != nil {
+			query.Set(name+"."+op, t.UTC().Format(time.RFC3339Nano))
+		}
+	}
+}
+
+//encodeList func adds limit and cursor to query
+func encodeList(query url.Values, limit int, cursor string) {
+
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+}
+
+//withQuery func return method with encoded query appended
+func withQuery(method string, query url.Values) string {
+
+	if len(query) == 0 {
+		return method
+	}
+
+	return method + "?" + query.Encode()
+}
+
+//pager struct is cursor state shared by list iterators
+type pager struct {
+	cursor  string
+	started bool
+	index   int
+	size    int
+	err     error
+}
+
+//next func advances to the next item, fetching pages by next_cursor when needed.
+//fetch return number of items in the page and its next_cursor
+func (p *pager) next(ctx context.Context, fetch func(ctx context.Context, cursor string) (int, string, error)) bool {
+
+	for {
+		if p.index+1 < p.size {
+			p.index++
+			return true
+		}
+
+		if p.err != nil || p.started && p.cursor == "" {
+			return false
+		}
+
+		size, cursor, err := fetch(ctx, p.cursor)
+		p.started = true
+		if err != nil {
+			p.err = err
+			return false
+		}
+
+		p.cursor, p.size, p.index = cursor, size, -1
+	}
+}
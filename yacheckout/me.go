@@ -1,6 +1,7 @@
 package yacheckout
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 )
@@ -14,8 +15,13 @@ type Me struct {
 
 //GetMe func receives me information Yandex.Checkout
 func (checkout *Checkout) GetMe(client *http.Client) (me *Me, apierr *Error, err error) {
+	return checkout.GetMeContext(context.Background(), client)
+}
+
+//GetMeContext func receives me information Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetMeContext(ctx context.Context, client *http.Client) (me *Me, apierr *Error, err error) {
 
-	b, apierr, err := checkout.Exec(APIEndpoint, client, http.MethodGet, nil, "me", nil)
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, "me", nil)
 	if err != nil || apierr != nil {
 		return
 	}
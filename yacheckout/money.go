@@ -0,0 +1,465 @@
+package yacheckout
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+//Money errors
+var (
+	ErrCurrencyMismatch = errors.New("yacheckout: currency mismatch")
+	ErrInvalidDecimal   = errors.New("yacheckout: invalid decimal")
+	ErrPrecision        = errors.New("yacheckout: value exceeds currency precision")
+	ErrInvalidRatios    = errors.New("yacheckout: ratios must be non-negative with positive sum")
+	ErrOverflow         = errors.New("yacheckout: amount overflows int64 minor units")
+	ErrDivisionByZero   = errors.New("yacheckout: division by zero")
+)
+
+//Currency minor unit exponents differing from 2.See https://www.iso.org/iso-4217-currency-codes.html
+var currencyExponents = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+//CurrencyExponent func return number of minor unit digits of ISO 4217 currency
+func CurrencyExponent(currency string) int {
+
+	if exp, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+
+	return 2
+}
+
+//Amount struct is payment.amount object.
+//Minor is exact value in minor units of Currency (kopecks for RUB)
+type Amount struct {
+	Minor    int64
+	Currency string
+}
+
+//NewAmount func return Amount of minor units
+func NewAmount(minor int64, currency string) Amount {
+	return Amount{Minor: minor, Currency: currency}
+}
+
+//ParseAmount func return Amount of decimal value such as "100.50"
+func ParseAmount(value, currency string) (amount Amount, err error) {
+
+	minor, err := parseFixed(value, CurrencyExponent(currency))
+	if err != nil {
+		return
+	}
+
+	return Amount{Minor: minor, Currency: currency}, nil
+}
+
+//SumAmounts func return sum of amounts of the same currency
+func SumAmounts(currency string, amounts ...Amount) (sum Amount, err error) {
+
+	sum.Currency = currency
+	for _, a := range amounts {
+		if sum, err = sum.Add(a); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+//Value func return decimal value of amount such as "100.50"
+func (amount Amount) Value() string {
+	return formatFixed(amount.Minor, CurrencyExponent(amount.Currency))
+}
+
+//String func implements fmt.Stringer
+func (amount Amount) String() string {
+	return amount.Value() + " " + amount.Currency
+}
+
+//IsZero func reports whether amount is zero
+func (amount Amount) IsZero() bool {
+	return amount.Minor == 0
+}
+
+//Sign func return -1, 0 or +1 by sign of amount
+func (amount Amount) Sign() int {
+
+	switch {
+	case amount.Minor < 0:
+		return -1
+	case amount.Minor > 0:
+		return 1
+	}
+
+	return 0
+}
+
+//Add func return amount + b, ErrOverflow when sum doesn't fit minor units
+func (amount Amount) Add(b Amount) (Amount, error) {
+
+	if err := amount.sameCurrency(b); err != nil {
+		return Amount{}, err
+	}
+
+	sum := amount.Minor + b.Minor
+	if b.Minor > 0 && sum < amount.Minor || b.Minor < 0 && sum > amount.Minor {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{Minor: sum, Currency: amount.Currency}, nil
+}
+
+//Sub func return amount - b, ErrOverflow when difference doesn't fit minor units
+func (amount Amount) Sub(b Amount) (Amount, error) {
+
+	if err := amount.sameCurrency(b); err != nil {
+		return Amount{}, err
+	}
+
+	diff := amount.Minor - b.Minor
+	if b.Minor > 0 && diff > amount.Minor || b.Minor < 0 && diff < amount.Minor {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{Minor: diff, Currency: amount.Currency}, nil
+}
+
+//Neg func return -amount, amount must be greater than math.MinInt64 minor units
+func (amount Amount) Neg() Amount {
+	return Amount{Minor: -amount.Minor, Currency: amount.Currency}
+}
+
+//Mul func return amount * n, ErrOverflow when product doesn't fit minor units
+func (amount Amount) Mul(n int64) (Amount, error) {
+	return amountOf(new(big.Int).Mul(big.NewInt(amount.Minor), big.NewInt(n)), amount.Currency)
+}
+
+//MulDecimal func return amount * d rounded half away from zero to minor units,
+//ErrOverflow is returned when result doesn't fit
+func (amount Amount) MulDecimal(d Decimal) (Amount, error) {
+
+	v := new(big.Int).Mul(big.NewInt(amount.Minor), big.NewInt(d.coef))
+	if d.scale > 0 {
+		div := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+		q, m := new(big.Int).QuoRem(v, div, new(big.Int))
+		if m.Abs(m).Mul(m, big.NewInt(2)).Cmp(div) >= 0 {
+			q.Add(q, big.NewInt(int64(v.Sign())))
+		}
+		v = q
+	}
+
+	return amountOf(v, amount.Currency)
+}
+
+//MulRatio func return amount * num / den rounded half away from zero to minor units,
+//ErrDivisionByZero is returned for zero den and ErrOverflow when result doesn't fit
+func (amount Amount) MulRatio(num, den int64) (Amount, error) {
+
+	if den == 0 {
+		return Amount{}, ErrDivisionByZero
+	}
+
+	v := new(big.Int).Mul(big.NewInt(amount.Minor), big.NewInt(num))
+	d := big.NewInt(den)
+	q, m := new(big.Int).QuoRem(v, d, new(big.Int))
+	if m.Abs(m).Mul(m, big.NewInt(2)).CmpAbs(d) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign()*d.Sign())))
+	}
+
+	return amountOf(q, amount.Currency)
+}
+
+//Cmp func return -1, 0 or +1 as amount is less, equal or greater than b
+func (amount Amount) Cmp(b Amount) (int, error) {
+
+	if err := amount.sameCurrency(b); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case amount.Minor < b.Minor:
+		return -1, nil
+	case amount.Minor > b.Minor:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+//Equal func reports whether amount and b have the same value and currency
+func (amount Amount) Equal(b Amount) bool {
+	return amount.Minor == b.Minor && amount.sameCurrency(b) == nil
+}
+
+//Allocate func splits amount proportionally to ratios without losing minor units.
+//Remainder is distributed by the largest remainder method, earlier parts win ties
+func (amount Amount) Allocate(ratios ...int64) (parts []Amount, err error) {
+
+	total := big.NewInt(0)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, ErrInvalidRatios
+		}
+		total.Add(total, big.NewInt(r))
+	}
+
+	if total.Sign() == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	value := new(big.Int).Abs(big.NewInt(amount.Minor))
+	quotients := make([]*big.Int, len(ratios))
+	remainders := make([]*big.Int, len(ratios))
+	left := new(big.Int).Set(value)
+
+	for i, r := range ratios {
+		quotients[i], remainders[i] = new(big.Int).QuoRem(new(big.Int).Mul(value, big.NewInt(r)), total, new(big.Int))
+		left.Sub(left, quotients[i])
+	}
+
+	for n := left.Int64(); n > 0; n-- {
+		best := -1
+		for i, m := range remainders {
+			if ratios[i] > 0 && (best < 0 || m.Cmp(remainders[best]) > 0) {
+				best = i
+			}
+		}
+		quotients[best].Add(quotients[best], big.NewInt(1))
+		remainders[best].SetInt64(-1)
+	}
+
+	parts = make([]Amount, len(ratios))
+	for i, q := range quotients {
+		if amount.Minor < 0 {
+			q.Neg(q)
+		}
+		parts[i] = Amount{Minor: q.Int64(), Currency: amount.Currency}
+	}
+
+	return
+}
+
+//Split func splits amount into n parts differing by at most one minor unit
+func (amount Amount) Split(n int) ([]Amount, error) {
+
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+
+	return amount.Allocate(ratios...)
+}
+
+//MarshalJSON func implements json.Marshaler
+func (amount Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Value    string `json:"value"`
+		Currency string `json:"currency"`
+	}{amount.Value(), amount.Currency})
+}
+
+//UnmarshalJSON func implements json.Unmarshaler, null leaves amount unchanged
+func (amount *Amount) UnmarshalJSON(b []byte) error {
+
+	if isNull(b) {
+		return nil
+	}
+
+	var aux struct {
+		Value    json.RawMessage `json:"value"`
+		Currency string          `json:"currency"`
+	}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	minor, err := parseFixed(unquote(aux.Value), CurrencyExponent(aux.Currency))
+	if err != nil {
+		return err
+	}
+
+	*amount = Amount{Minor: minor, Currency: aux.Currency}
+	return nil
+}
+
+//amountOf func return Amount of v minor units, ErrOverflow when v doesn't fit int64
+func amountOf(v *big.Int, currency string) (Amount, error) {
+
+	if !v.IsInt64() {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{Minor: v.Int64(), Currency: currency}, nil
+}
+
+func (amount Amount) sameCurrency(b Amount) error {
+
+	if !strings.EqualFold(amount.Currency, b.Currency) {
+		return ErrCurrencyMismatch
+	}
+
+	return nil
+}
+
+//Decimal struct is exact fixed-point number such as excise or quantity
+type Decimal struct {
+	coef  int64
+	scale int
+}
+
+//NewDecimal func return Decimal of coef * 10^-scale
+func NewDecimal(coef int64, scale int) Decimal {
+	return Decimal{coef: coef, scale: scale}
+}
+
+//ParseDecimal func return Decimal of string such as "1.250"
+func ParseDecimal(s string) (d Decimal, err error) {
+
+	scale := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		scale = len(s) - i - 1
+	}
+
+	coef, err := parseFixed(s, scale)
+	if err != nil {
+		return
+	}
+
+	return Decimal{coef: coef, scale: scale}, nil
+}
+
+//String func implements fmt.Stringer
+func (d Decimal) String() string {
+	return formatFixed(d.coef, d.scale)
+}
+
+//IsZero func reports whether d is zero
+func (d Decimal) IsZero() bool {
+	return d.coef == 0
+}
+
+//Sign func return -1, 0 or +1 by sign of d
+func (d Decimal) Sign() int {
+
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	}
+
+	return 0
+}
+
+//Scale func return number of significant fractional digits of d
+func (d Decimal) Scale() int {
+
+	coef, scale := d.coef, d.scale
+	for scale > 0 && coef%10 == 0 {
+		coef /= 10
+		scale--
+	}
+
+	return scale
+}
+
+//MarshalJSON func implements json.Marshaler
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+//UnmarshalJSON func implements json.Unmarshaler, null leaves d unchanged
+func (d *Decimal) UnmarshalJSON(b []byte) (err error) {
+
+	if isNull(b) {
+		return nil
+	}
+
+	*d, err = ParseDecimal(unquote(b))
+	return
+}
+
+//isNull func reports whether b is JSON null
+func isNull(b []byte) bool {
+	return string(bytes.TrimSpace(b)) == "null"
+}
+
+//unquote func return JSON string or number as string
+func unquote(b []byte) string {
+
+	b = bytes.TrimSpace(b)
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		b = b[1 : len(b)-1]
+	}
+
+	return string(b)
+}
+
+//parseFixed func return s scaled by 10^scale as integer
+func parseFixed(s string, scale int) (int64, error) {
+
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg, s = s[0] == '-', s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	if whole == "" && frac == "" || strings.ContainsAny(whole+frac, ".+-") {
+		return 0, ErrInvalidDecimal
+	}
+
+	if len(frac) > scale {
+		if strings.Trim(frac[scale:], "0") != "" {
+			return 0, ErrPrecision
+		}
+		frac = frac[:scale]
+	}
+
+	digits := whole + frac + strings.Repeat("0", scale-len(frac))
+	if digits == "" {
+		digits = "0"
+	}
+
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidDecimal
+	}
+
+	if neg {
+		v = -v
+	}
+
+	return v, nil
+}
+
+//formatFixed func return v scaled by 10^-scale as string
+func formatFixed(v int64, scale int) string {
+
+	sign := ""
+	u := strconv.FormatUint(uint64(v), 10)
+	if v < 0 {
+		sign = "-"
+		u = strconv.FormatUint(uint64(-v), 10)
+	}
+
+	if scale <= 0 {
+		return sign + u
+	}
+
+	if len(u) <= scale {
+		u = strings.Repeat("0", scale-len(u)+1) + u
+	}
+
+	return sign + u[:len(u)-scale] + "." + u[len(u)-scale:]
+}
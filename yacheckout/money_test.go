@@ -0,0 +1,329 @@
+package yacheckout
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseFixed(t *testing.T) {
+
+	tests := []struct {
+		s     string
+		scale int
+		want  int64
+		err   error
+	}{
+		{"0", 2, 0, nil},
+		{"1", 2, 100, nil},
+		{"1.5", 2, 150, nil},
+		{"1.50", 2, 150, nil},
+		{"1.500", 2, 150, nil},
+		{".5", 2, 50, nil},
+		{"5.", 2, 500, nil},
+		{"-1.05", 2, -105, nil},
+		{"+1.05", 2, 105, nil},
+		{"-0.01", 2, -1, nil},
+		{"12", 0, 12, nil},
+		{"0.001", 3, 1, nil},
+		{"92233720368547758.07", 2, math.MaxInt64, nil},
+		{"-92233720368547758.07", 2, -math.MaxInt64, nil},
+		{"1.005", 2, 0, ErrPrecision},
+		{"0.1", 0, 0, ErrPrecision},
+		{"92233720368547758.08", 2, 0, ErrInvalidDecimal},
+		{"", 2, 0, ErrInvalidDecimal},
+		{".", 2, 0, ErrInvalidDecimal},
+		{"-", 2, 0, ErrInvalidDecimal},
+		{"+", 2, 0, ErrInvalidDecimal},
+		{"-+1", 2, 0, ErrInvalidDecimal},
+		{"+-1", 2, 0, ErrInvalidDecimal},
+		{"--1", 2, 0, ErrInvalidDecimal},
+		{"++1", 2, 0, ErrInvalidDecimal},
+		{"1-", 2, 0, ErrInvalidDecimal},
+		{"1.-5", 2, 0, ErrInvalidDecimal},
+		{"1.2.3", 2, 0, ErrInvalidDecimal},
+		{"1e2", 2, 0, ErrInvalidDecimal},
+		{" 1", 2, 0, ErrInvalidDecimal},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFixed(tt.s, tt.scale)
+		if got != tt.want || err != tt.err {
+			t.Errorf("parseFixed(%q, %d) = %d, %v; want %d, %v", tt.s, tt.scale, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestFormatFixed(t *testing.T) {
+
+	tests := []struct {
+		v     int64
+		scale int
+		want  string
+	}{
+		{0, 2, "0.00"},
+		{1, 2, "0.01"},
+		{-1, 2, "-0.01"},
+		{100, 2, "1.00"},
+		{-12345, 2, "-123.45"},
+		{7, 0, "7"},
+		{1, 3, "0.001"},
+		{math.MaxInt64, 2, "92233720368547758.07"},
+		{math.MinInt64, 2, "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := formatFixed(tt.v, tt.scale); got != tt.want {
+			t.Errorf("formatFixed(%d, %d) = %q; want %q", tt.v, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+
+	tests := []struct {
+		value, currency string
+		want            Amount
+		err             error
+	}{
+		{"10.5", "RUB", Amount{Minor: 1050, Currency: "RUB"}, nil},
+		{"-10.5", "RUB", Amount{Minor: -1050, Currency: "RUB"}, nil},
+		{"10", "JPY", Amount{Minor: 10, Currency: "JPY"}, nil},
+		{"10.5", "JPY", Amount{}, ErrPrecision},
+		{"1.234", "KWD", Amount{Minor: 1234, Currency: "KWD"}, nil},
+		{"1.234", "kwd", Amount{Minor: 1234, Currency: "kwd"}, nil},
+		{"abc", "RUB", Amount{}, ErrInvalidDecimal},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.value, tt.currency)
+		if err != tt.err || err == nil && got != tt.want {
+			t.Errorf("ParseAmount(%q, %q) = %v, %v; want %v, %v", tt.value, tt.currency, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+
+	rub := func(minor int64) Amount { return NewAmount(minor, "RUB") }
+
+	tests := []struct {
+		name string
+		op   func() (Amount, error)
+		want int64
+		err  error
+	}{
+		{"Add", func() (Amount, error) { return rub(150).Add(NewAmount(50, "rub")) }, 200, nil},
+		{"Add negative", func() (Amount, error) { return rub(150).Add(rub(-200)) }, -50, nil},
+		{"Add currency", func() (Amount, error) { return rub(150).Add(NewAmount(1, "USD")) }, 0, ErrCurrencyMismatch},
+		{"Add overflow", func() (Amount, error) { return rub(math.MaxInt64).Add(rub(1)) }, 0, ErrOverflow},
+		{"Add underflow", func() (Amount, error) { return rub(math.MinInt64).Add(rub(-1)) }, 0, ErrOverflow},
+		{"Sub", func() (Amount, error) { return rub(150).Sub(rub(200)) }, -50, nil},
+		{"Sub min", func() (Amount, error) { return rub(-1).Sub(rub(math.MinInt64)) }, math.MaxInt64, nil},
+		{"Sub overflow", func() (Amount, error) { return rub(0).Sub(rub(math.MinInt64)) }, 0, ErrOverflow},
+		{"Sub underflow", func() (Amount, error) { return rub(math.MinInt64).Sub(rub(1)) }, 0, ErrOverflow},
+		{"Mul", func() (Amount, error) { return rub(-7).Mul(3) }, -21, nil},
+		{"Mul overflow", func() (Amount, error) { return rub(math.MaxInt64 / 2).Mul(3) }, 0, ErrOverflow},
+		{"Mul min", func() (Amount, error) { return rub(math.MinInt64).Mul(-1) }, 0, ErrOverflow},
+		{"MulDecimal half up", func() (Amount, error) { return rub(5).MulDecimal(NewDecimal(5, 1)) }, 3, nil},
+		{"MulDecimal half away from zero", func() (Amount, error) { return rub(-5).MulDecimal(NewDecimal(5, 1)) }, -3, nil},
+		{"MulDecimal down", func() (Amount, error) { return rub(100).MulDecimal(NewDecimal(333, 3)) }, 33, nil},
+		{"MulDecimal even", func() (Amount, error) { return rub(4).MulDecimal(NewDecimal(5, 1)) }, 2, nil},
+		{"MulDecimal integer", func() (Amount, error) { return rub(150).MulDecimal(NewDecimal(3, 0)) }, 450, nil},
+		{"MulDecimal large", func() (Amount, error) { return rub(math.MaxInt64).MulDecimal(NewDecimal(1000, 3)) }, math.MaxInt64, nil},
+		{"MulDecimal overflow", func() (Amount, error) { return rub(math.MaxInt64).MulDecimal(NewDecimal(1001, 3)) }, 0, ErrOverflow},
+		{"MulRatio half up", func() (Amount, error) { return rub(5).MulRatio(1, 2) }, 3, nil},
+		{"MulRatio half away from zero", func() (Amount, error) { return rub(-5).MulRatio(1, 2) }, -3, nil},
+		{"MulRatio negative den", func() (Amount, error) { return rub(5).MulRatio(1, -2) }, -3, nil},
+		{"MulRatio below half", func() (Amount, error) { return rub(5).MulRatio(1, 3) }, 2, nil},
+		{"MulRatio above half", func() (Amount, error) { return rub(5).MulRatio(2, 3) }, 3, nil},
+		{"MulRatio large", func() (Amount, error) { return rub(math.MaxInt64).MulRatio(math.MaxInt64, math.MaxInt64) }, math.MaxInt64, nil},
+		{"MulRatio zero den", func() (Amount, error) { return rub(5).MulRatio(1, 0) }, 0, ErrDivisionByZero},
+		{"MulRatio overflow", func() (Amount, error) { return rub(math.MaxInt64).MulRatio(3, 2) }, 0, ErrOverflow},
+		{"MulRatio min", func() (Amount, error) { return rub(math.MinInt64).MulRatio(-1, 1) }, 0, ErrOverflow},
+		{"SumAmounts", func() (Amount, error) { return SumAmounts("RUB", rub(1), rub(2), rub(3)) }, 6, nil},
+		{"SumAmounts overflow", func() (Amount, error) { return SumAmounts("RUB", rub(math.MaxInt64), rub(1), rub(-1)) }, 0, ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.op()
+		if err != tt.err || err == nil && (got.Minor != tt.want || got.Currency != "RUB") {
+			t.Errorf("%s = %v, %v; want %d, %v", tt.name, got, err, tt.want, tt.err)
+		}
+	}
+
+	if cmp, err := rub(150).Cmp(rub(151)); err != nil || cmp != -1 {
+		t.Errorf("Cmp = %d, %v; want -1", cmp, err)
+	}
+
+	if _, err := rub(150).Cmp(NewAmount(150, "USD")); err != ErrCurrencyMismatch {
+		t.Errorf("Cmp of USD = %v; want %v", err, ErrCurrencyMismatch)
+	}
+
+	if !rub(150).Equal(NewAmount(150, "rub")) || rub(150).Equal(NewAmount(150, "USD")) {
+		t.Error("Equal ignores currency case only")
+	}
+}
+
+func TestAllocate(t *testing.T) {
+
+	tests := []struct {
+		minor  int64
+		ratios []int64
+		want   []int64
+		err    error
+	}{
+		{100, []int64{1, 1, 1}, []int64{34, 33, 33}, nil},
+		{-100, []int64{1, 1, 1}, []int64{-34, -33, -33}, nil},
+		{101, []int64{1, 1}, []int64{51, 50}, nil},
+		{100, []int64{1, 2}, []int64{33, 67}, nil},
+		{5, []int64{3, 3, 4}, []int64{2, 1, 2}, nil},
+		{1, []int64{0, 1, 1}, []int64{0, 1, 0}, nil},
+		{2, []int64{0, 0, 5}, []int64{0, 0, 2}, nil},
+		{0, []int64{1, 2}, []int64{0, 0}, nil},
+		{math.MaxInt64, []int64{math.MaxInt64, math.MaxInt64}, []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2}, nil},
+		{math.MinInt64, []int64{1}, []int64{math.MinInt64}, nil},
+		{math.MinInt64, []int64{1, 1}, []int64{math.MinInt64 / 2, math.MinInt64 / 2}, nil},
+		{100, []int64{0, 0}, nil, ErrInvalidRatios},
+		{100, []int64{1, -1}, nil, ErrInvalidRatios},
+		{100, nil, nil, ErrInvalidRatios},
+	}
+
+	for _, tt := range tests {
+		parts, err := NewAmount(tt.minor, "RUB").Allocate(tt.ratios...)
+		if err != tt.err {
+			t.Errorf("Allocate(%d, %v) error = %v; want %v", tt.minor, tt.ratios, err, tt.err)
+			continue
+		}
+
+		sum := NewAmount(0, "RUB")
+		for i, part := range parts {
+			if part.Minor != tt.want[i] || part.Currency != "RUB" {
+				t.Errorf("Allocate(%d, %v) = %v; want %v", tt.minor, tt.ratios, parts, tt.want)
+				break
+			}
+			sum, _ = sum.Add(part)
+		}
+
+		if err == nil && sum.Minor != tt.minor {
+			t.Errorf("Allocate(%d, %v) sums to %d", tt.minor, tt.ratios, sum.Minor)
+		}
+	}
+
+	if parts, err := NewAmount(10, "RUB").Split(3); err != nil || len(parts) != 3 || parts[0].Minor != 4 || parts[1].Minor != 3 || parts[2].Minor != 3 {
+		t.Errorf("Split(3) = %v, %v", parts, err)
+	}
+
+	if _, err := NewAmount(10, "RUB").Split(0); err != ErrInvalidRatios {
+		t.Errorf("Split(0) = %v; want %v", err, ErrInvalidRatios)
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+
+	tests := []struct {
+		s    string
+		want string
+		err  error
+	}{
+		{"1.250", "1.250", nil},
+		{"-0.5", "-0.5", nil},
+		{"+3", "3", nil},
+		{"0.000", "0.000", nil},
+		{"-+3", "", ErrInvalidDecimal},
+		{"1..0", "", ErrInvalidDecimal},
+		{"", "", ErrInvalidDecimal},
+	}
+
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.s)
+		if err != tt.err || err == nil && d.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, %v; want %s, %v", tt.s, d, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+
+	type object struct {
+		Amount  Amount  `json:"amount"`
+		Decimal Decimal `json:"decimal"`
+	}
+
+	want := object{Amount: NewAmount(10050, "RUB"), Decimal: NewDecimal(15, 1)}
+	b, err := json.Marshal(want)
+	if err != nil || string(b) != `{"amount":{"value":"100.50","currency":"RUB"},"decimal":"1.5"}` {
+		t.Fatalf("Marshal = %s, %v", b, err)
+	}
+
+	var got object
+	if err = json.Unmarshal(b, &got); err != nil || got != want {
+		t.Errorf("Unmarshal(%s) = %+v, %v", b, got, err)
+	}
+
+	if err = json.Unmarshal([]byte(`{"amount":null,"decimal":null}`), &got); err != nil || got != want {
+		t.Errorf("Unmarshal of null = %+v, %v; want unchanged", got, err)
+	}
+
+	tests := []struct {
+		s    string
+		want Amount
+		err  error
+	}{
+		{`{"value":"10.00","currency":"RUB"}`, NewAmount(1000, "RUB"), nil},
+		{`{"value":10,"currency":"RUB"}`, NewAmount(1000, "RUB"), nil},
+		{`{"value":"10","currency":"JPY"}`, NewAmount(10, "JPY"), nil},
+		{`{"value":"10.001","currency":"RUB"}`, Amount{}, ErrPrecision},
+		{`{"value":"-+1","currency":"RUB"}`, Amount{}, ErrInvalidDecimal},
+	}
+
+	for _, tt := range tests {
+		var amount Amount
+		if err = json.Unmarshal([]byte(tt.s), &amount); err != tt.err || amount != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v, %v", tt.s, amount, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestDecimalSignScale(t *testing.T) {
+
+	tests := []struct {
+		s           string
+		sign, scale int
+	}{
+		{"0", 0, 0},
+		{"0.000", 0, 0},
+		{"1.250", 1, 2},
+		{"-0.5", -1, 1},
+		{"3", 1, 0},
+		{"10.000", 1, 0},
+	}
+
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.s)
+		if err != nil || d.Sign() != tt.sign || d.Scale() != tt.scale {
+			t.Errorf("ParseDecimal(%q) sign, scale = %d, %d, %v; want %d, %d", tt.s, d.Sign(), d.Scale(), err, tt.sign, tt.scale)
+		}
+	}
+}
+
+func TestItemTotal(t *testing.T) {
+
+	item := &Item{Amount: NewAmount(9999, "RUB"), Quantity: NewDecimal(1500, 3)}
+	if total, err := item.Total(); err != nil || total.Minor != 14999 {
+		t.Errorf("Total of 1.5 x 99.99 = %v, %v; want 149.99", total, err)
+	}
+
+	item = &Item{Amount: NewAmount(math.MaxInt64, "RUB"), Quantity: NewDecimal(2, 0)}
+	if _, err := item.Total(); err != ErrOverflow {
+		t.Errorf("Total of overflowing item = %v; want %v", err, ErrOverflow)
+	}
+
+	receipt := &Receipt{Items: []Item{
+		{Amount: NewAmount(10000, "RUB"), Quantity: NewDecimal(2, 0)},
+		{Amount: NewAmount(333, "RUB"), Quantity: NewDecimal(1, 1)},
+	}}
+	if total, err := receipt.Total("RUB"); err != nil || total.Minor != 20033 {
+		t.Errorf("Receipt.Total = %v, %v; want 200.33", total, err)
+	}
+}
@@ -0,0 +1,236 @@
+package yacheckout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+//Notification errors
+var (
+	ErrNotNotification = errors.New("yacheckout: not a notification")
+	ErrUnknownEvent    = errors.New("yacheckout: unknown notification event")
+	ErrNoObject        = errors.New("yacheckout: notification has no object")
+)
+
+//DefaultMaxNotificationSize is NotificationHandler.MaxBodySize used when it is zero
+const DefaultMaxNotificationSize = 1 << 20
+
+//Notification struct is incoming Yandex.Checkout notification object.
+//Object is decoded into Payment, Refund, Payout or Deal by Event.See https://kassa.yandex.ru/developers/using-api/webhooks
+type Notification struct {
+	Type    string          `json:"type"`
+	Event   string          `json:"event"`
+	Object  json.RawMessage `json:"object"`
+	Payment *Payment        `json:"-"`
+	Refund  *Refund         `json:"-"`
+	Payout  *Payout         `json:"-"`
+	Deal    *Deal           `json:"-"`
+}
+
+//ParseNotification func decodes notification body
+func ParseNotification(b []byte) (notification *Notification, err error) {
+
+	if err = json.Unmarshal(b, &notification); err != nil {
+		return nil, err
+	}
+
+	if notification == nil || notification.Type != "notification" {
+		return nil, ErrNotNotification
+	}
+
+	return notification, notification.decodeObject()
+}
+
+//decodeObject func decodes Object into Payment, Refund, Payout or Deal by Event, missing or null object is ErrNoObject
+func (notification *Notification) decodeObject() (err error) {
+
+	object := notification.Object
+	if len(object) == 0 {
+		object = json.RawMessage("null")
+	}
+
+	switch {
+	case strings.HasPrefix(notification.Event, "payment."):
+		err = json.Unmarshal(object, &notification.Payment)
+	case strings.HasPrefix(notification.Event, "refund."):
+		err = json.Unmarshal(object, &notification.Refund)
+	case strings.HasPrefix(notification.Event, "payout."):
+		err = json.Unmarshal(object, &notification.Payout)
+	case strings.HasPrefix(notification.Event, "deal."):
+		err = json.Unmarshal(object, &notification.Deal)
+	}
+
+	if err == nil && notification.noObject() {
+		err = ErrNoObject
+	}
+
+	return
+}
+
+//noObject func reports whether object of Event isn't decoded
+func (notification *Notification) noObject() bool {
+
+	switch {
+	case strings.HasPrefix(notification.Event, "payment."):
+		return notification.Payment == nil
+	case strings.HasPrefix(notification.Event, "refund."):
+		return notification.Refund == nil
+	case strings.HasPrefix(notification.Event, "payout."):
+		return notification.Payout == nil
+	case strings.HasPrefix(notification.Event, "deal."):
+		return notification.Deal == nil
+	}
+
+	return false
+}
+
+//NotificationHandler struct is http.Handler of notifications calling callback of event.
+//Response is 200 when callback succeeds or event has no callback, so that gateway stops retrying,
+//500 when callback fails, so that notification is delivered again, and 4xx for malformed requests.
+//When Verifier is set, notifications from untrusted addresses are rejected with 403,
+//stale ones are acknowledged without callbacks and OnReject is called for both
+type NotificationHandler struct {
+	OnPaymentWaitingForCapture func(ctx context.Context, payment *Payment) error
+	OnPaymentSucceeded         func(ctx context.Context, payment *Payment) error
+	OnPaymentCanceled          func(ctx context.Context, payment *Payment) error
+	OnRefundSucceeded          func(ctx context.Context, refund *Refund) error
+	OnPayoutSucceeded          func(ctx context.Context, payout *Payout) error
+	OnPayoutCanceled           func(ctx context.Context, payout *Payout) error
+	OnDealClosed               func(ctx context.Context, deal *Deal) error
+	//OnNotification is called for every notification before callback of event
+	OnNotification func(ctx context.Context, notification *Notification) error
+	MaxBodySize    int64
+	Verifier       *NotificationVerifier
+	OnReject       func(r *http.Request, notification *Notification, err error)
+}
+
+//ServeHTTP func implements http.Handler
+func (handler *NotificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if handler.Verifier != nil {
+		if err := handler.Verifier.VerifyAddress(r); err != nil {
+			handler.reject(w, r, nil, err, http.StatusForbidden)
+			return
+		}
+	}
+
+	limit := handler.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxNotificationSize
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if int64(len(b)) > limit {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	notification, err := ParseNotification(b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if handler.Verifier != nil {
+		switch err = handler.Verifier.VerifyObject(r.Context(), notification); err {
+		case nil:
+		case ErrStaleNotification, ErrUnverifiableObject:
+			handler.reject(w, r, notification, err, http.StatusOK)
+			return
+		default:
+			handler.reject(w, r, notification, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err = handler.Dispatch(r.Context(), notification); err != nil && err != ErrUnknownEvent {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+//Dispatch func calls OnNotification and callback of notification event.
+//It return ErrUnknownEvent for events not known to this package
+//and ErrNoObject without calling anything when object of event is missing
+func (handler *NotificationHandler) Dispatch(ctx context.Context, notification *Notification) error {
+
+	if notification.noObject() {
+		return ErrNoObject
+	}
+
+	if handler.OnNotification != nil {
+		if err := handler.OnNotification(ctx, notification); err != nil {
+			return err
+		}
+	}
+
+	var onPayment func(ctx context.Context, payment *Payment) error
+	var onPayout func(ctx context.Context, payout *Payout) error
+
+	switch notification.Event {
+	case PaymentWaitingForCapture:
+		onPayment = handler.OnPaymentWaitingForCapture
+	case PaymentSucceeded:
+		onPayment = handler.OnPaymentSucceeded
+	case PaymentCanceled:
+		onPayment = handler.OnPaymentCanceled
+	case RefundSucceeded:
+		if handler.OnRefundSucceeded != nil {
+			return handler.OnRefundSucceeded(ctx, notification.Refund)
+		}
+		return nil
+	case PayoutSucceeded:
+		onPayout = handler.OnPayoutSucceeded
+	case PayoutCanceled:
+		onPayout = handler.OnPayoutCanceled
+	case DealClosed:
+		if handler.OnDealClosed != nil {
+			return handler.OnDealClosed(ctx, notification.Deal)
+		}
+		return nil
+	default:
+		return ErrUnknownEvent
+	}
+
+	switch {
+	case onPayment != nil:
+		return onPayment(ctx, notification.Payment)
+	case onPayout != nil:
+		return onPayout(ctx, notification.Payout)
+	}
+
+	return nil
+}
+
+//reject func calls OnReject and responds with status
+func (handler *NotificationHandler) reject(w http.ResponseWriter, r *http.Request, notification *Notification, err error, status int) {
+
+	if handler.OnReject != nil {
+		handler.OnReject(r, notification, err)
+	}
+
+	if status == http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
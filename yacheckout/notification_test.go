@@ -0,0 +1,123 @@
+package yacheckout
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseNotification(t *testing.T) {
+
+	tests := []struct {
+		body string
+		err  error
+	}{
+		{`{"type":"notification","event":"payment.succeeded","object":{"id":"p1","status":"succeeded"}}`, nil},
+		{`{"type":"notification","event":"refund.succeeded","object":{"id":"r1","payment_id":"p1"}}`, nil},
+		{`{"type":"notification","event":"payout.succeeded","object":{"id":"po-1","status":"succeeded"}}`, nil},
+		{`{"type":"notification","event":"deal.closed","object":{"id":"dl-1","status":"closed"}}`, nil},
+		{`{"type":"notification","event":"payment.succeeded"}`, ErrNoObject},
+		{`{"type":"notification","event":"deal.closed","object":null}`, ErrNoObject},
+		{`{"type":"notification","event":"payout.canceled","object":null}`, ErrNoObject},
+		{`{"type":"notification","event":"payment.succeeded","object":null}`, ErrNoObject},
+		{`{"type":"notification","event":"refund.succeeded","object":null}`, ErrNoObject},
+		{`{"type":"notification","event":"unknown.event"}`, nil},
+		{`{"type":"other","event":"payment.succeeded","object":{}}`, ErrNotNotification},
+		{`null`, ErrNotNotification},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseNotification([]byte(tt.body)); err != tt.err {
+			t.Errorf("ParseNotification(%s) = %v; want %v", tt.body, err, tt.err)
+		}
+	}
+}
+
+func TestDispatch(t *testing.T) {
+
+	var called []string
+	handler := &NotificationHandler{
+		OnPaymentSucceeded: func(ctx context.Context, payment *Payment) error {
+			called = append(called, "payment "+payment.ID)
+			return nil
+		},
+		OnRefundSucceeded: func(ctx context.Context, refund *Refund) error {
+			called = append(called, "refund "+refund.ID)
+			return nil
+		},
+		OnPayoutCanceled: func(ctx context.Context, payout *Payout) error {
+			called = append(called, "payout "+payout.ID)
+			return nil
+		},
+		OnDealClosed: func(ctx context.Context, deal *Deal) error {
+			called = append(called, "deal "+deal.ID)
+			return nil
+		},
+		OnNotification: func(ctx context.Context, notification *Notification) error {
+			called = append(called, notification.Event)
+			return nil
+		},
+	}
+
+	tests := []struct {
+		notification *Notification
+		called       string
+		err          error
+	}{
+		{&Notification{Event: PaymentSucceeded, Payment: &Payment{ID: "p1"}}, "payment.succeeded,payment p1", nil},
+		{&Notification{Event: RefundSucceeded, Refund: &Refund{ID: "r1"}}, "refund.succeeded,refund r1", nil},
+		{&Notification{Event: PaymentCanceled, Payment: &Payment{ID: "p1"}}, "payment.canceled", nil},
+		{&Notification{Event: PayoutCanceled, Payout: &Payout{ID: "po-1"}}, "payout.canceled,payout po-1", nil},
+		{&Notification{Event: PayoutSucceeded, Payout: &Payout{ID: "po-1"}}, "payout.succeeded", nil},
+		{&Notification{Event: DealClosed, Deal: &Deal{ID: "dl-1"}}, "deal.closed,deal dl-1", nil},
+		{&Notification{Event: PaymentSucceeded}, "", ErrNoObject},
+		{&Notification{Event: DealClosed}, "", ErrNoObject},
+		{&Notification{Event: PayoutSucceeded}, "", ErrNoObject},
+		{&Notification{Event: RefundSucceeded}, "", ErrNoObject},
+		{&Notification{Event: "unknown.event"}, "unknown.event", ErrUnknownEvent},
+	}
+
+	for _, tt := range tests {
+		called = nil
+		if err := handler.Dispatch(context.Background(), tt.notification); err != tt.err || strings.Join(called, ",") != tt.called {
+			t.Errorf("Dispatch(%s) = %v, called %v; want %v, called %s", tt.notification.Event, err, called, tt.err, tt.called)
+		}
+	}
+}
+
+func TestNotificationHandler(t *testing.T) {
+
+	handler := &NotificationHandler{
+		OnPaymentSucceeded: func(ctx context.Context, payment *Payment) error {
+			if payment.ID == "fail" {
+				return context.Canceled
+			}
+			return nil
+		},
+		MaxBodySize: 256,
+	}
+
+	tests := []struct {
+		method string
+		body   string
+		status int
+	}{
+		{http.MethodPost, `{"type":"notification","event":"payment.succeeded","object":{"id":"p1"}}`, http.StatusOK},
+		{http.MethodPost, `{"type":"notification","event":"payment.succeeded","object":{"id":"fail"}}`, http.StatusInternalServerError},
+		{http.MethodPost, `{"type":"notification","event":"payment.succeeded","object":null}`, http.StatusBadRequest},
+		{http.MethodPost, `{"type":"notification","event":"unknown.event","object":{}}`, http.StatusOK},
+		{http.MethodPost, `{`, http.StatusBadRequest},
+		{http.MethodPost, `{"type":"notification","object":"` + strings.Repeat("x", 256) + `"}`, http.StatusRequestEntityTooLarge},
+		{http.MethodGet, ``, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s %s = %d; want %d", tt.method, tt.body, w.Code, tt.status)
+		}
+	}
+}
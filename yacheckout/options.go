@@ -0,0 +1,74 @@
+package yacheckout
+
+import (
+	"net/http"
+	"time"
+)
+
+//Option func configures Checkout, see NewCheckout and Checkout.With
+type Option func(checkout *Checkout)
+
+//WithBaseURL func return Option setting base URL of API such as APIEndpoint
+func WithBaseURL(endpoint string) Option {
+	return func(checkout *Checkout) {
+		checkout.Endpoint = endpoint
+	}
+}
+
+//WithHTTPClient func return Option setting client used when operations get nil client
+func WithHTTPClient(client *http.Client) Option {
+	return func(checkout *Checkout) {
+		checkout.HTTPClient = client
+	}
+}
+
+//WithTimeout func return Option limiting duration of a single attempt
+func WithTimeout(timeout time.Duration) Option {
+	return func(checkout *Checkout) {
+		checkout.Timeout = timeout
+	}
+}
+
+//WithUserAgent func return Option setting User-Agent header
+func WithUserAgent(userAgent string) Option {
+	return func(checkout *Checkout) {
+		checkout.UserAgent = userAgent
+	}
+}
+
+//WithHeader func return Option adding header sent with every request
+func WithHeader(key, value string) Option {
+	return func(checkout *Checkout) {
+		header := checkout.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Add(key, value)
+		checkout.Header = header
+	}
+}
+
+//WithRetryPolicy func return Option setting retry policy, nil disables retries
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(checkout *Checkout) {
+		checkout.Retry = policy
+	}
+}
+
+//WithPaymentStates func return Option setting last known payment states, nil disables checks of them
+func WithPaymentStates(states *PaymentStates) Option {
+	return func(checkout *Checkout) {
+		checkout.States = states
+	}
+}
+
+//With func return copy of checkout configured by opts, checkout itself is left unchanged
+func (checkout *Checkout) With(opts ...Option) *Checkout {
+
+	c := *checkout
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return &c
+}
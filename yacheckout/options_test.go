@@ -0,0 +1,41 @@
+package yacheckout
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckoutWith(t *testing.T) {
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	base := NewCheckout(100500, "test_secret", "", WithBaseURL(srv.URL+"/"), WithHeader("X-Base", "1"), WithTimeout(time.Second))
+	derived := base.With(WithUserAgent("agent/1.0"), WithHeader("X-Call", "2"), WithTimeout(0))
+
+	if base.UserAgent != "" || base.Header.Get("X-Call") != "" || base.Timeout != time.Second {
+		t.Fatalf("With changed base checkout: %+v", base)
+	}
+
+	if _, _, err := derived.Exec("", nil, http.MethodGet, nil, "me", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Get("User-Agent") != "agent/1.0" || got.Get("X-Base") != "1" || got.Get("X-Call") != "2" {
+		t.Errorf("derived checkout sent %v", got)
+	}
+
+	if _, _, err := base.Exec("", nil, http.MethodGet, nil, "me", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Get("User-Agent") == "agent/1.0" || got.Get("X-Call") != "" {
+		t.Errorf("base checkout sent %v", got)
+	}
+}
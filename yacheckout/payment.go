@@ -1,8 +1,10 @@
 package yacheckout
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,15 +13,17 @@ import (
 //Payment struct is Yandex.Checkout payment object
 type Payment struct {
 	ID                   string                `json:"id,omitempty"`
-	Status               string                `json:"status,omitempty"`
+	Status               PaymentStatus         `json:"status,omitempty"`
 	Amount               *Amount               `json:"amount,omitempty"`
 	Description          string                `json:"description,omitempty"`
 	Receipt              *Receipt              `json:"receipt,omitempty"`
 	Recipient            *Recipient            `json:"recipient,omitempty"`
+	Transfers            []Transfer            `json:"transfers,omitempty"`
+	Deal                 *DealInfo             `json:"deal,omitempty"`
 	Requestor            *Requestor            `json:"requestor,omitempty"`
 	PaymentToken         string                `json:"payment_token,omitempty"`
 	PaymentMethodID      string                `json:"payment_method_id,omitempty"`
-	PaymentMethodData    *PaymentMethod        `json:"payment_method_data,omitempty"`
+	PaymentMethodData    PaymentMethodData     `json:"payment_method_data,omitempty"`
 	PaymentMethod        *PaymentMethod        `json:"payment_method,omitempty"`
 	CapturedAt           *time.Time            `json:"captured_at,string,omitempty"`
 	CreatedAt            *time.Time            `json:"created_at,string,omitempty"`
@@ -39,10 +43,71 @@ type Payment struct {
 	Airline              *Airline              `json:"airline,omitempty"`
 }
 
-//Amount struct is payment.amount object
-type Amount struct {
-	Value    float64 `json:"value,string"`
-	Currency string  `json:"currency"`
+//UnmarshalJSON func implements json.Unmarshaler decoding payment_method_data by its type
+func (payment *Payment) UnmarshalJSON(b []byte) error {
+
+	type raw Payment
+	aux := struct {
+		*raw
+		PaymentMethodData json.RawMessage `json:"payment_method_data,omitempty"`
+	}{raw: (*raw)(payment)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.PaymentMethodData) == 0 || string(aux.PaymentMethodData) == "null" {
+		payment.PaymentMethodData = nil
+		return nil
+	}
+
+	data, err := UnmarshalPaymentMethodData(aux.PaymentMethodData)
+	if err != nil {
+		return err
+	}
+
+	payment.PaymentMethodData = data
+	return nil
+}
+
+//Payments struct is Yandex.Checkout payments list object
+type Payments struct {
+	Type       string    `json:"type"`
+	Items      []Payment `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+//PaymentsFilter struct is payments list filter.See https://kassa.yandex.ru/developers/api#get_payments_list
+type PaymentsFilter struct {
+	CreatedAt     TimeRange
+	CapturedAt    TimeRange
+	PaymentMethod string
+	Status        PaymentStatus
+	Limit         int
+	Cursor        string
+}
+
+//Query func return filter as URL query
+func (filter *PaymentsFilter) Query() url.Values {
+
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+
+	filter.CreatedAt.encode(query, "created_at")
+	filter.CapturedAt.encode(query, "captured_at")
+
+	if filter.PaymentMethod != "" {
+		query.Set("payment_method", filter.PaymentMethod)
+	}
+
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+
+	encodeList(query, filter.Limit, filter.Cursor)
+	return query
 }
 
 //Recipient struct is payment.recipient object
@@ -77,6 +142,18 @@ type PaymentMethod struct {
 	PaymentMethodToken  string            `json:"payment_method_token,omitempty"`
 }
 
+//Data func return typed payment method data of method such as *BankCardData.
+//Fields of response only, such as ID and Saved, are left in method
+func (method *PaymentMethod) Data() (PaymentMethodData, error) {
+
+	b, err := json.Marshal(method)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalPaymentMethodData(b)
+}
+
 //Card struct is payment.payment_method.card object
 type Card struct {
 	Number        uint64 `json:"number,string"`
@@ -112,21 +189,19 @@ type VatData struct {
 	Rate   uint8   `json:"rate,string,omitempty"`
 }
 
-//Confirmation struct is payment.confirmation object
+//Confirmation struct is payment.confirmation object, see ConfirmationScenario for typed variants
 type Confirmation struct {
-	Type             string `json:"type"`
-	ConfirmationData string `json:"confirmation_data,omitempty"`
-	Locale           string `json:"locale,omitempty"`
-	ConfirmationURL  string `json:"confirmation_url,omitempty"`
-	Enforce          bool   `json:"enforce,omitempty"`
-	ReturnURL        string `json:"return_url,omitempty"`
+	Type              string `json:"type"`
+	ConfirmationData  string `json:"confirmation_data,omitempty"`
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	ConfirmationURL   string `json:"confirmation_url,omitempty"`
+	Enforce           bool   `json:"enforce,omitempty"`
+	ReturnURL         string `json:"return_url,omitempty"`
 }
 
-//RefundedAmount struct is payment.refunded_amount object
-type RefundedAmount struct {
-	Value    string `json:"value"`
-	Currency string `json:"currency"`
-}
+//RefundedAmount is payment.refunded_amount object
+type RefundedAmount = Amount
 
 //CancellationDetails struct is payment.cancellation_details object
 type CancellationDetails struct {
@@ -164,58 +239,189 @@ type Leg struct {
 
 //CreatePayment func create payment Yandex.Checkout
 func (checkout *Checkout) CreatePayment(client *http.Client, V4UUID *uuid.UUID, pay *Payment) (payment *Payment, apierr *Error, err error) {
+	return checkout.CreatePaymentContext(context.Background(), client, V4UUID, pay)
+}
+
+//CreatePaymentContext func create payment Yandex.Checkout bound to ctx
+func (checkout *Checkout) CreatePaymentContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, pay *Payment) (payment *Payment, apierr *Error, err error) {
+
+	if pay.Confirmation != nil {
+		if err = pay.Confirmation.Validate(); err != nil {
+			return
+		}
+	}
+
+	if pay.PaymentMethodData != nil {
+		if err = pay.PaymentMethodData.Validate(); err != nil {
+			return
+		}
+	}
+
+	if len(pay.Transfers) > 0 && pay.Amount != nil {
+		if err = ValidateTransfers(pay.Transfers, *pay.Amount); err != nil {
+			return
+		}
+	}
 
 	b, err := json.Marshal(pay)
 	if err != nil {
 		return
 	}
 
-	b, apierr, err = checkout.Exec(APIEndpoint, client, http.MethodPost, V4UUID, "payments", b)
+	b, apierr, err = checkout.exec(ctx, client, http.MethodPost, V4UUID, "payments", b)
 	if err != nil || apierr != nil {
 		return
 	}
 
-	err = json.Unmarshal(b, &payment)
+	if err = json.Unmarshal(b, &payment); err == nil {
+		checkout.States.Record(payment)
+	}
 	return
 }
 
 //GetPayment func receives payment information Yandex.Checkout
 func (checkout *Checkout) GetPayment(client *http.Client, id string) (payment *Payment, apierr *Error, err error) {
+	return checkout.GetPaymentContext(context.Background(), client, id)
+}
 
-	b, apierr, err := checkout.Exec(APIEndpoint, client, http.MethodGet, nil, "payments/"+id, nil)
+//GetPaymentContext func receives payment information Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetPaymentContext(ctx context.Context, client *http.Client, id string) (payment *Payment, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, "payments/"+url.PathEscape(id), nil)
 	if err != nil || apierr != nil {
 		return
 	}
 
-	err = json.Unmarshal(b, &payment)
+	if err = json.Unmarshal(b, &payment); err == nil {
+		checkout.States.Record(payment)
+	}
 	return
 }
 
 //CapturePayment func confirm payment Yandex.Checkout
 func (checkout *Checkout) CapturePayment(client *http.Client, V4UUID *uuid.UUID, id string, pay *Payment) (payment *Payment, apierr *Error, err error) {
+	return checkout.CapturePaymentContext(context.Background(), client, V4UUID, id, pay)
+}
+
+//CapturePaymentContext func confirm payment Yandex.Checkout bound to ctx.
+//Transfers of pay must sum to its amount.
+//*StateError is returned without request when checkout.States knows payment can't be captured or pay amount exceeds it
+func (checkout *Checkout) CapturePaymentContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, id string, pay *Payment) (payment *Payment, apierr *Error, err error) {
+
+	var amount *Amount
+	if pay != nil {
+		amount = pay.Amount
+	}
+
+	if err = checkout.States.Check(id, CaptureOperation, amount); err != nil {
+		return
+	}
+
+	if pay != nil && len(pay.Transfers) > 0 && pay.Amount != nil {
+		if err = ValidateTransfers(pay.Transfers, *pay.Amount); err != nil {
+			return
+		}
+	}
 
 	b, err := json.Marshal(pay)
 	if err != nil {
 		return
 	}
 
-	b, apierr, err = checkout.Exec(APIEndpoint, client, http.MethodPost, V4UUID, "payments/"+id+"/capture", b)
+	b, apierr, err = checkout.exec(ctx, client, http.MethodPost, V4UUID, "payments/"+url.PathEscape(id)+"/capture", b)
 	if err != nil || apierr != nil {
 		return
 	}
 
-	err = json.Unmarshal(b, &payment)
+	if err = json.Unmarshal(b, &payment); err == nil {
+		checkout.States.Record(payment)
+	}
 	return
 }
 
 //CancelPayment func cancel payment Yandex.Checkout
 func (checkout *Checkout) CancelPayment(client *http.Client, V4UUID *uuid.UUID, id string) (payment *Payment, apierr *Error, err error) {
+	return checkout.CancelPaymentContext(context.Background(), client, V4UUID, id)
+}
 
-	b, apierr, err := checkout.Exec(APIEndpoint, client, http.MethodPost, V4UUID, "payments/"+id+"/cancel", []byte("{ }"))
+//CancelPaymentContext func cancel payment Yandex.Checkout bound to ctx.
+//*StateError is returned without request when checkout.States knows payment can't be canceled
+func (checkout *Checkout) CancelPaymentContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, id string) (payment *Payment, apierr *Error, err error) {
+
+	if err = checkout.States.Check(id, CancelOperation, nil); err != nil {
+		return
+	}
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodPost, V4UUID, "payments/"+url.PathEscape(id)+"/cancel", []byte("{ }"))
 	if err != nil || apierr != nil {
 		return
 	}
 
-	err = json.Unmarshal(b, &payment)
+	if err = json.Unmarshal(b, &payment); err == nil {
+		checkout.States.Record(payment)
+	}
 	return
 }
+
+//ListPayments func receives payments list Yandex.Checkout
+func (checkout *Checkout) ListPayments(client *http.Client, filter *PaymentsFilter) (payments *Payments, apierr *Error, err error) {
+	return checkout.ListPaymentsContext(context.Background(), client, filter)
+}
+
+//ListPaymentsContext func receives payments list Yandex.Checkout bound to ctx
+func (checkout *Checkout) ListPaymentsContext(ctx context.Context, client *http.Client, filter *PaymentsFilter) (payments *Payments, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, withQuery("payments", filter.Query()), nil)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &payments)
+	return
+}
+
+//PaymentIterator struct iterates payments list following next_cursor
+type PaymentIterator struct {
+	checkout *Checkout
+	client   *http.Client
+	filter   PaymentsFilter
+	page     []Payment
+	pager    pager
+}
+
+//IteratePayments func return iterator over all payments matching filter
+func (checkout *Checkout) IteratePayments(client *http.Client, filter *PaymentsFilter) *PaymentIterator {
+
+	it := &PaymentIterator{checkout: checkout, client: client}
+	if filter != nil {
+		it.filter = *filter
+		it.pager.cursor = filter.Cursor
+	}
+
+	return it
+}
+
+//Next func advances iterator, it return false when payments are over or on error
+func (it *PaymentIterator) Next(ctx context.Context) bool {
+	return it.pager.next(ctx, func(ctx context.Context, cursor string) (int, string, error) {
+
+		it.filter.Cursor = cursor
+		payments, apierr, err := it.checkout.ListPaymentsContext(ctx, it.client, &it.filter)
+		if err = joinError(apierr, err); err != nil {
+			return 0, "", err
+		}
+
+		it.page = payments.Items
+		return len(it.page), payments.NextCursor, nil
+	})
+}
+
+//Payment func return current payment
+func (it *PaymentIterator) Payment() *Payment {
+	return &it.page[it.pager.index]
+}
+
+//Err func return error stopped iteration, *Error for response errors
+func (it *PaymentIterator) Err() error {
+	return it.pager.err
+}
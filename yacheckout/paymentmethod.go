@@ -0,0 +1,579 @@
+package yacheckout
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+//VAT data types of b2b_sberbank.See https://kassa.yandex.ru/developers/api#create_payment_payment_method_data_b2b_sberbank_vat_data
+const (
+	Calculated = "calculated"
+	Mixed      = "mixed"
+	Untaxed    = "untaxed"
+)
+
+//ErrUnknownPaymentMethod is returned for payment_method_data of unknown type
+var ErrUnknownPaymentMethod = errors.New("yacheckout: unknown payment method type")
+
+//PaymentMethodData is typed payment.payment_method_data object.
+//Implementations are BankCardData, SberbankData, B2BSberbankData, ApplePayData, GooglePayData,
+//MobileBalanceData, YandexMoneyData, AlfabankData, QiwiData, WebmoneyData, WeChatData,
+//TinkoffBankData, InstallmentsData and CashData, all used by pointer.
+//Nil data is marshalled as null and fails validation
+type PaymentMethodData interface {
+	PaymentMethodType() string
+	Validate() error
+	paymentMethodData()
+}
+
+//BankCardData struct is bank_card payment method data, Card is omitted when payer enters it on payment page
+type BankCardData struct {
+	Card *Card `json:"card,omitempty"`
+}
+
+//SberbankData struct is sberbank (SberPay) payment method data
+type SberbankData struct {
+	Phone string `json:"phone,omitempty"`
+}
+
+//B2BSberbankData struct is b2b_sberbank payment method data
+type B2BSberbankData struct {
+	PaymentPurpose string   `json:"payment_purpose"`
+	VatData        *VatData `json:"vat_data"`
+}
+
+//ApplePayData struct is apple_pay payment method data
+type ApplePayData struct {
+	PaymentData string `json:"payment_data"`
+}
+
+//GooglePayData struct is google_pay payment method data
+type GooglePayData struct {
+	PaymentMethodToken  string `json:"payment_method_token"`
+	GoogleTransactionID string `json:"google_transaction_id"`
+}
+
+//MobileBalanceData struct is mobile_balance payment method data
+type MobileBalanceData struct {
+	Phone string `json:"phone"`
+}
+
+//YandexMoneyData struct is yandex_money payment method data
+type YandexMoneyData struct{}
+
+//AlfabankData struct is alfabank payment method data, Login is needed for external confirmation
+type AlfabankData struct {
+	Login string `json:"login,omitempty"`
+}
+
+//QiwiData struct is qiwi payment method data
+type QiwiData struct {
+	Phone string `json:"phone,omitempty"`
+}
+
+//WebmoneyData struct is webmoney payment method data
+type WebmoneyData struct{}
+
+//WeChatData struct is wechat payment method data
+type WeChatData struct{}
+
+//TinkoffBankData struct is tinkoff_bank payment method data
+type TinkoffBankData struct{}
+
+//InstallmentsData struct is installments payment method data
+type InstallmentsData struct{}
+
+//CashData struct is cash payment method data
+type CashData struct {
+	Phone string `json:"phone,omitempty"`
+}
+
+//UnmarshalPaymentMethodData func decodes payment_method_data object by its type
+func UnmarshalPaymentMethodData(b []byte) (data PaymentMethodData, err error) {
+
+	var head struct {
+		Type string `json:"type"`
+	}
+
+	if err = json.Unmarshal(b, &head); err != nil {
+		return
+	}
+
+	switch head.Type {
+	case BankCard:
+		data = &BankCardData{}
+	case Sberbank:
+		data = &SberbankData{}
+	case B2BSberbank:
+		data = &B2BSberbankData{}
+	case ApplePay:
+		data = &ApplePayData{}
+	case GooglePay:
+		data = &GooglePayData{}
+	case MobileBalance:
+		data = &MobileBalanceData{}
+	case YandexMoney:
+		data = &YandexMoneyData{}
+	case Alfabank:
+		data = &AlfabankData{}
+	case Qiwi:
+		data = &QiwiData{}
+	case Webmoney:
+		data = &WebmoneyData{}
+	case WeChat:
+		data = &WeChatData{}
+	case TinkoffBank:
+		data = &TinkoffBankData{}
+	case Installments:
+		data = &InstallmentsData{}
+	case Cash:
+		data = &CashData{}
+	default:
+		return nil, ErrUnknownPaymentMethod
+	}
+
+	err = json.Unmarshal(b, data)
+	return
+}
+
+//marshalWithType func marshals v with "type" member prepended
+func marshalWithType(typ string, v interface{}) ([]byte, error) {
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	t, _ := json.Marshal(typ)
+	head := append([]byte(`{"type":`), t...)
+	if len(b) <= 2 {
+		return append(head, '}'), nil
+	}
+
+	return append(append(head, ','), b[1:]...), nil
+}
+
+//PaymentMethodType func implements PaymentMethodData
+func (*BankCardData) PaymentMethodType() string { return BankCard }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*SberbankData) PaymentMethodType() string { return Sberbank }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*B2BSberbankData) PaymentMethodType() string { return B2BSberbank }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*ApplePayData) PaymentMethodType() string { return ApplePay }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*GooglePayData) PaymentMethodType() string { return GooglePay }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*MobileBalanceData) PaymentMethodType() string { return MobileBalance }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*YandexMoneyData) PaymentMethodType() string { return YandexMoney }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*AlfabankData) PaymentMethodType() string { return Alfabank }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*QiwiData) PaymentMethodType() string { return Qiwi }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*WebmoneyData) PaymentMethodType() string { return Webmoney }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*WeChatData) PaymentMethodType() string { return WeChat }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*TinkoffBankData) PaymentMethodType() string { return TinkoffBank }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*InstallmentsData) PaymentMethodType() string { return Installments }
+
+//PaymentMethodType func implements PaymentMethodData
+func (*CashData) PaymentMethodType() string { return Cash }
+
+func (*BankCardData) paymentMethodData()      {}
+func (*SberbankData) paymentMethodData()      {}
+func (*B2BSberbankData) paymentMethodData()   {}
+func (*ApplePayData) paymentMethodData()      {}
+func (*GooglePayData) paymentMethodData()     {}
+func (*MobileBalanceData) paymentMethodData() {}
+func (*YandexMoneyData) paymentMethodData()   {}
+func (*AlfabankData) paymentMethodData()      {}
+func (*QiwiData) paymentMethodData()          {}
+func (*WebmoneyData) paymentMethodData()      {}
+func (*WeChatData) paymentMethodData()        {}
+func (*TinkoffBankData) paymentMethodData()   {}
+func (*InstallmentsData) paymentMethodData()  {}
+func (*CashData) paymentMethodData()          {}
+
+//MarshalJSON func implements json.Marshaler
+func (data *BankCardData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw BankCardData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *SberbankData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw SberbankData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *B2BSberbankData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw B2BSberbankData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *ApplePayData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw ApplePayData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *GooglePayData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw GooglePayData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *MobileBalanceData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw MobileBalanceData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *YandexMoneyData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	return marshalWithType(data.PaymentMethodType(), struct{}{})
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *AlfabankData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw AlfabankData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *QiwiData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw QiwiData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *WebmoneyData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	return marshalWithType(data.PaymentMethodType(), struct{}{})
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *WeChatData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	return marshalWithType(data.PaymentMethodType(), struct{}{})
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *TinkoffBankData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	return marshalWithType(data.PaymentMethodType(), struct{}{})
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *InstallmentsData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	return marshalWithType(data.PaymentMethodType(), struct{}{})
+}
+
+//MarshalJSON func implements json.Marshaler
+func (data *CashData) MarshalJSON() ([]byte, error) {
+
+	if data == nil {
+		return []byte("null"), nil
+	}
+
+	type raw CashData
+	return marshalWithType(data.PaymentMethodType(), raw(*data))
+}
+
+//Validate func checks card data when it is entered by merchant
+func (data *BankCardData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	switch {
+	case data.Card == nil:
+		return nil
+	case data.Card.Number == 0:
+		return &ValidationError{Parameter: "payment_method_data.card.number", Description: "required"}
+	case data.Card.ExpiryYear == 0:
+		return &ValidationError{Parameter: "payment_method_data.card.expiry_year", Description: "required"}
+	case len(data.Card.ExpiryMonth) != 2:
+		return &ValidationError{Parameter: "payment_method_data.card.expiry_month", Description: "must be in MM format"}
+	}
+
+	return nil
+}
+
+//Validate func checks phone format when it is set
+func (data *SberbankData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return validatePhone(data.Phone, false)
+}
+
+//Validate func checks that payment_purpose and vat_data are set
+func (data *B2BSberbankData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	switch {
+	case data.PaymentPurpose == "":
+		return &ValidationError{Parameter: "payment_method_data.payment_purpose", Description: "required"}
+	case len([]rune(data.PaymentPurpose)) > 210:
+		return &ValidationError{Parameter: "payment_method_data.payment_purpose", Description: "longer than 210 characters"}
+	case data.VatData == nil:
+		return &ValidationError{Parameter: "payment_method_data.vat_data", Description: "required"}
+	}
+
+	return data.VatData.Validate()
+}
+
+//Validate func checks that payment_data is set
+func (data *ApplePayData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	if data.PaymentData == "" {
+		return &ValidationError{Parameter: "payment_method_data.payment_data", Description: "required"}
+	}
+
+	return nil
+}
+
+//Validate func checks that payment_method_token and google_transaction_id are set
+func (data *GooglePayData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	switch {
+	case data.PaymentMethodToken == "":
+		return &ValidationError{Parameter: "payment_method_data.payment_method_token", Description: "required"}
+	case data.GoogleTransactionID == "":
+		return &ValidationError{Parameter: "payment_method_data.google_transaction_id", Description: "required"}
+	}
+
+	return nil
+}
+
+//Validate func checks that phone is set
+func (data *MobileBalanceData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return validatePhone(data.Phone, true)
+}
+
+//Validate func implements PaymentMethodData
+func (data *YandexMoneyData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return nil
+}
+
+//Validate func implements PaymentMethodData
+func (data *AlfabankData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return nil
+}
+
+//Validate func checks phone format when it is set
+func (data *QiwiData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return validatePhone(data.Phone, false)
+}
+
+//Validate func implements PaymentMethodData
+func (data *WebmoneyData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return nil
+}
+
+//Validate func implements PaymentMethodData
+func (data *WeChatData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return nil
+}
+
+//Validate func implements PaymentMethodData
+func (data *TinkoffBankData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return nil
+}
+
+//Validate func implements PaymentMethodData
+func (data *InstallmentsData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return nil
+}
+
+//Validate func checks phone format when it is set
+func (data *CashData) Validate() error {
+
+	if data == nil {
+		return errNoPaymentMethodData()
+	}
+
+	return validatePhone(data.Phone, false)
+}
+
+//Validate func checks vat_data of b2b_sberbank by its type
+func (vat *VatData) Validate() error {
+
+	switch {
+	case vat.Type != Calculated && vat.Type != Mixed && vat.Type != Untaxed:
+		return &ValidationError{Parameter: "payment_method_data.vat_data.type", Description: "must be calculated, mixed or untaxed"}
+	case vat.Type == Calculated && vat.Rate == 0:
+		return &ValidationError{Parameter: "payment_method_data.vat_data.rate", Description: "required for calculated VAT"}
+	case vat.Type != Untaxed && vat.Amount == nil:
+		return &ValidationError{Parameter: "payment_method_data.vat_data.amount", Description: "required for " + vat.Type + " VAT"}
+	}
+
+	return nil
+}
+
+//errNoPaymentMethodData func return error of nil PaymentMethodData
+func errNoPaymentMethodData() error {
+	return &ValidationError{Parameter: "payment_method_data", Description: "required"}
+}
+
+//validatePhone func checks phone in ITU-T E.164 format such as 79000000000
+func validatePhone(phone string, required bool) error {
+
+	if phone == "" {
+		if required {
+			return &ValidationError{Parameter: "payment_method_data.phone", Description: "required"}
+		}
+		return nil
+	}
+
+	if len(phone) < 11 || len(phone) > 15 || !digits(phone) {
+		return &ValidationError{Parameter: "payment_method_data.phone", Description: "must be in ITU-T E.164 format"}
+	}
+
+	return nil
+}
+
+func digits(s string) bool {
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,137 @@
+package yacheckout
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPaymentMethodDataJSON(t *testing.T) {
+
+	tests := []struct {
+		data PaymentMethodData
+		json string
+	}{
+		{&BankCardData{}, `{"type":"bank_card"}`},
+		{&BankCardData{Card: &Card{Number: 5555555555554477, ExpiryYear: 2030, ExpiryMonth: "07", CSC: "123"}},
+			`{"type":"bank_card","card":{"number":"5555555555554477","expiry_year":"2030","expiry_month":"07","csc":"123"}}`},
+		{&SberbankData{Phone: "79000000000"}, `{"type":"sberbank","phone":"79000000000"}`},
+		{&B2BSberbankData{PaymentPurpose: "Goods", VatData: &VatData{Type: Untaxed}}, `{"type":"b2b_sberbank","payment_purpose":"Goods","vat_data":{"type":"untaxed"}}`},
+		{&ApplePayData{PaymentData: "token"}, `{"type":"apple_pay","payment_data":"token"}`},
+		{&GooglePayData{PaymentMethodToken: "token", GoogleTransactionID: "id"}, `{"type":"google_pay","payment_method_token":"token","google_transaction_id":"id"}`},
+		{&MobileBalanceData{Phone: "79000000000"}, `{"type":"mobile_balance","phone":"79000000000"}`},
+		{&YandexMoneyData{}, `{"type":"yandex_money"}`},
+		{&AlfabankData{Login: "user"}, `{"type":"alfabank","login":"user"}`},
+		{&QiwiData{}, `{"type":"qiwi"}`},
+		{&WebmoneyData{}, `{"type":"webmoney"}`},
+		{&WeChatData{}, `{"type":"wechat"}`},
+		{&TinkoffBankData{}, `{"type":"tinkoff_bank"}`},
+		{&InstallmentsData{}, `{"type":"installments"}`},
+		{&CashData{Phone: "79000000000"}, `{"type":"cash","phone":"79000000000"}`},
+	}
+
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.data)
+		if err != nil || string(b) != tt.json {
+			t.Errorf("Marshal(%T) = %s, %v; want %s", tt.data, b, err, tt.json)
+			continue
+		}
+
+		data, err := UnmarshalPaymentMethodData(b)
+		if err != nil || !reflect.DeepEqual(data, tt.data) {
+			t.Errorf("UnmarshalPaymentMethodData(%s) = %#v, %v; want %#v", b, data, err, tt.data)
+		}
+	}
+
+	if _, err := UnmarshalPaymentMethodData([]byte(`{"type":"unknown"}`)); err != ErrUnknownPaymentMethod {
+		t.Errorf("UnmarshalPaymentMethodData of unknown type = %v; want %v", err, ErrUnknownPaymentMethod)
+	}
+}
+
+func TestPaymentMethodDataNil(t *testing.T) {
+
+	var data PaymentMethodData = (*BankCardData)(nil)
+
+	if b, err := json.Marshal(&Payment{PaymentMethodData: data}); err != nil || string(b) != `{"payment_method_data":null}` {
+		t.Errorf("Marshal of typed nil = %s, %v", b, err)
+	}
+
+	nils := []PaymentMethodData{
+		(*BankCardData)(nil), (*SberbankData)(nil), (*B2BSberbankData)(nil), (*ApplePayData)(nil),
+		(*GooglePayData)(nil), (*MobileBalanceData)(nil), (*YandexMoneyData)(nil), (*AlfabankData)(nil),
+		(*QiwiData)(nil), (*WebmoneyData)(nil), (*WeChatData)(nil), (*TinkoffBankData)(nil),
+		(*InstallmentsData)(nil), (*CashData)(nil),
+	}
+
+	for _, data := range nils {
+		verr, ok := data.Validate().(*ValidationError)
+		if !ok || verr.Parameter != "payment_method_data" {
+			t.Errorf("Validate of nil %T = %v", data, data.Validate())
+		}
+	}
+}
+
+func TestPaymentMethodDataValidate(t *testing.T) {
+
+	card := func(number uint64, year uint16, month string) *BankCardData {
+		return &BankCardData{Card: &Card{Number: number, ExpiryYear: year, ExpiryMonth: month}}
+	}
+
+	tests := []struct {
+		data      PaymentMethodData
+		parameter string
+	}{
+		{&BankCardData{}, ""},
+		{card(5555555555554477, 2030, "07"), ""},
+		{card(0, 2030, "07"), "payment_method_data.card.number"},
+		{card(5555555555554477, 0, "07"), "payment_method_data.card.expiry_year"},
+		{card(5555555555554477, 2030, "7"), "payment_method_data.card.expiry_month"},
+		{&SberbankData{}, ""},
+		{&SberbankData{Phone: "+79000000000"}, "payment_method_data.phone"},
+		{&B2BSberbankData{VatData: &VatData{Type: Untaxed}}, "payment_method_data.payment_purpose"},
+		{&B2BSberbankData{PaymentPurpose: "Goods"}, "payment_method_data.vat_data"},
+		{&B2BSberbankData{PaymentPurpose: "Goods", VatData: &VatData{Type: "other"}}, "payment_method_data.vat_data.type"},
+		{&B2BSberbankData{PaymentPurpose: "Goods", VatData: &VatData{Type: Calculated}}, "payment_method_data.vat_data.rate"},
+		{&ApplePayData{}, "payment_method_data.payment_data"},
+		{&GooglePayData{PaymentMethodToken: "token"}, "payment_method_data.google_transaction_id"},
+		{&MobileBalanceData{}, "payment_method_data.phone"},
+		{&MobileBalanceData{Phone: "7900"}, "payment_method_data.phone"},
+		{&MobileBalanceData{Phone: "79000000000"}, ""},
+		{&QiwiData{Phone: "7900000000a"}, "payment_method_data.phone"},
+		{&CashData{Phone: "79000000000"}, ""},
+		{&YandexMoneyData{}, ""},
+	}
+
+	for _, tt := range tests {
+		err := tt.data.Validate()
+		verr, _ := err.(*ValidationError)
+		if tt.parameter == "" && err != nil || tt.parameter != "" && (verr == nil || verr.Parameter != tt.parameter) {
+			t.Errorf("Validate(%#v) = %v; want parameter %q", tt.data, err, tt.parameter)
+		}
+	}
+}
+
+func TestPaymentMethodData(t *testing.T) {
+
+	var payment Payment
+	body := `{"id":"p1","payment_method":{"type":"bank_card","id":"m1","saved":true,"card":{"first6":"555555","last4":"4477","expiry_year":"2030","expiry_month":"07","card_type":"MasterCard"}}}`
+	if err := json.Unmarshal([]byte(body), &payment); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := payment.PaymentMethod.Data()
+	card, ok := data.(*BankCardData)
+	if err != nil || !ok || card.Card == nil || card.Card.Last4 != "4477" || card.Card.First6 != 555555 || card.Card.ExpiryMonth != "07" {
+		t.Fatalf("Data = %#v, %v", data, err)
+	}
+
+	method := &PaymentMethod{Type: Sberbank, Phone: 79000000000}
+	if data, err = method.Data(); err != nil || !reflect.DeepEqual(data, &SberbankData{Phone: "79000000000"}) {
+		t.Errorf("Data of sberbank = %#v, %v", data, err)
+	}
+
+	method = &PaymentMethod{Type: "unknown"}
+	if _, err = method.Data(); err != ErrUnknownPaymentMethod {
+		t.Errorf("Data of unknown type = %v; want %v", err, ErrUnknownPaymentMethod)
+	}
+}
@@ -0,0 +1,187 @@
+package yacheckout
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//Payout destination types.See https://kassa.yandex.ru/developers/api#payout_object_payout_destination
+const (
+	YooMoney = "yoo_money"
+	SBP      = "sbp"
+)
+
+//Payout cancellation details reason.See https://kassa.yandex.ru/developers/payouts/declined-payouts
+const (
+	OneTimeLimitExceeded  = "one_time_limit_exceeded"
+	PeriodicLimitExceeded = "periodic_limit_exceeded"
+	RejectedByPayee       = "rejected_by_payee"
+	RecipientNotFound     = "recipient_not_found"
+	RecipientCheckFailed  = "recipient_check_failed"
+)
+
+//Payout struct is Yandex.Checkout payout object.
+//Payouts are made with credentials of payout gateway: agent ID as ShopID and its secret key
+type Payout struct {
+	ID                    string               `json:"id,omitempty"`
+	Amount                *Amount              `json:"amount,omitempty"`
+	Status                PayoutStatus         `json:"status,omitempty"`
+	PayoutToken           string               `json:"payout_token,omitempty"`
+	PayoutDestinationData *PayoutDestination   `json:"payout_destination_data,omitempty"`
+	PayoutDestination     *PayoutDestination   `json:"payout_destination,omitempty"`
+	Description           string               `json:"description,omitempty"`
+	CreatedAt             *time.Time           `json:"created_at,string,omitempty"`
+	CancellationDetails   *CancellationDetails `json:"cancellation_details,omitempty"`
+	Deal                  *DealInfo            `json:"deal,omitempty"`
+	Metadata              interface{}          `json:"metadata,omitempty"`
+	Test                  bool                 `json:"test,omitempty"`
+}
+
+//PayoutDestination struct is payout.payout_destination object, fields are set according to Type
+type PayoutDestination struct {
+	Type             string      `json:"type"`
+	Card             *PayoutCard `json:"card,omitempty"`
+	AccountNumber    string      `json:"account_number,omitempty"`
+	Phone            string      `json:"phone,omitempty"`
+	BankID           string      `json:"bank_id,omitempty"`
+	RecipientChecked bool        `json:"recipient_checked,omitempty"`
+}
+
+//PayoutCard struct is payout.payout_destination.card object, Number is sent only in requests
+type PayoutCard struct {
+	Number        string `json:"number,omitempty"`
+	First6        string `json:"first6,omitempty"`
+	Last4         string `json:"last4,omitempty"`
+	CardType      string `json:"card_type,omitempty"`
+	IssuerCountry string `json:"issuer_country,omitempty"`
+	IssuerName    string `json:"issuer_name,omitempty"`
+}
+
+//NewBankCardDestination func return payout destination of bank card number
+func NewBankCardDestination(number string) *PayoutDestination {
+	return &PayoutDestination{Type: BankCard, Card: &PayoutCard{Number: number}}
+}
+
+//NewYooMoneyDestination func return payout destination of YooMoney wallet number
+func NewYooMoneyDestination(accountNumber string) *PayoutDestination {
+	return &PayoutDestination{Type: YooMoney, AccountNumber: accountNumber}
+}
+
+//NewSBPDestination func return payout destination of SBP phone in ITU-T E.164 format at bank bankID
+func NewSBPDestination(phone, bankID string) *PayoutDestination {
+	return &PayoutDestination{Type: SBP, Phone: phone, BankID: bankID}
+}
+
+//Validate func checks destination data before sending
+func (destination *PayoutDestination) Validate() error {
+
+	const prefix = "payout_destination_data."
+
+	switch destination.Type {
+	case BankCard:
+		if destination.Card == nil || destination.Card.Number == "" {
+			return &ValidationError{Parameter: prefix + "card.number", Description: "required"}
+		}
+		if n := len(destination.Card.Number); n < 16 || n > 19 || !digits(destination.Card.Number) {
+			return &ValidationError{Parameter: prefix + "card.number", Description: "must be 16 to 19 digits"}
+		}
+	case YooMoney:
+		if n := len(destination.AccountNumber); n < 11 || n > 33 || !digits(destination.AccountNumber) {
+			return &ValidationError{Parameter: prefix + "account_number", Description: "must be 11 to 33 digits"}
+		}
+	case SBP:
+		if n := len(destination.Phone); n < 11 || n > 15 || !digits(destination.Phone) {
+			return &ValidationError{Parameter: prefix + "phone", Description: "must be in ITU-T E.164 format"}
+		}
+		if destination.BankID == "" {
+			return &ValidationError{Parameter: prefix + "bank_id", Description: "required"}
+		}
+	case "":
+		return &ValidationError{Parameter: prefix + "type", Description: "required"}
+	default:
+		return &ValidationError{Parameter: prefix + "type", Description: "unknown payout destination " + destination.Type}
+	}
+
+	return nil
+}
+
+//Validate func checks payout creation request before sending
+func (payout *Payout) Validate() error {
+
+	var errs ValidationErrors
+
+	switch {
+	case payout.Amount == nil:
+		errs.Add(&ValidationError{Parameter: "amount", Description: "required"})
+	case payout.Amount.Sign() <= 0:
+		errs.Add(&ValidationError{Parameter: "amount.value", Description: "must be positive"})
+	case payout.Amount.Currency == "":
+		errs.Add(&ValidationError{Parameter: "amount.currency", Description: "required"})
+	}
+
+	switch {
+	case payout.PayoutToken == "" && payout.PayoutDestinationData == nil:
+		errs.Add(&ValidationError{Parameter: "payout_destination_data", Description: "one of payout_token and payout_destination_data is required"})
+	case payout.PayoutToken != "" && payout.PayoutDestinationData != nil:
+		errs.Add(&ValidationError{Parameter: "payout_destination_data", Description: "only one of payout_token and payout_destination_data is allowed"})
+	case payout.PayoutDestinationData != nil:
+		errs.Add(payout.PayoutDestinationData.Validate())
+	}
+
+	if n := len([]rune(payout.Description)); n > MaxDescriptionLength {
+		errs.Add(&ValidationError{Parameter: "description", Description: "longer than " + strconv.Itoa(MaxDescriptionLength) + " characters"})
+	}
+
+	errs.Add(validateMetadata(payout.Metadata))
+	return errs.Err()
+}
+
+//CreatePayout func create payout Yandex.Checkout
+func (checkout *Checkout) CreatePayout(client *http.Client, V4UUID *uuid.UUID, pout *Payout) (payout *Payout, apierr *Error, err error) {
+	return checkout.CreatePayoutContext(context.Background(), client, V4UUID, pout)
+}
+
+//CreatePayoutContext func create payout Yandex.Checkout bound to ctx.
+//Request is validated before sending, retries reuse V4UUID so payout is made once
+func (checkout *Checkout) CreatePayoutContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, pout *Payout) (payout *Payout, apierr *Error, err error) {
+
+	if err = pout.Validate(); err != nil {
+		return
+	}
+
+	b, err := json.Marshal(pout)
+	if err != nil {
+		return
+	}
+
+	b, apierr, err = checkout.exec(ctx, client, http.MethodPost, V4UUID, "payouts", b)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &payout)
+	return
+}
+
+//GetPayout func receives payout information Yandex.Checkout
+func (checkout *Checkout) GetPayout(client *http.Client, id string) (payout *Payout, apierr *Error, err error) {
+	return checkout.GetPayoutContext(context.Background(), client, id)
+}
+
+//GetPayoutContext func receives payout information Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetPayoutContext(ctx context.Context, client *http.Client, id string) (payout *Payout, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, "payouts/"+url.PathEscape(id), nil)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &payout)
+	return
+}
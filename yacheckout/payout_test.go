@@ -0,0 +1,79 @@
+package yacheckout
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPayoutDestinationValidate(t *testing.T) {
+
+	tests := []struct {
+		destination *PayoutDestination
+		parameter   string
+	}{
+		{NewBankCardDestination("5555555555554477"), ""},
+		{NewBankCardDestination("5555555555554477123"), ""},
+		{NewBankCardDestination(""), "payout_destination_data.card.number"},
+		{NewBankCardDestination("555555555555447"), "payout_destination_data.card.number"},
+		{NewBankCardDestination("555555555555447x"), "payout_destination_data.card.number"},
+		{&PayoutDestination{Type: BankCard}, "payout_destination_data.card.number"},
+		{NewYooMoneyDestination("41001614575714"), ""},
+		{NewYooMoneyDestination("4100"), "payout_destination_data.account_number"},
+		{NewSBPDestination("79000000000", "100000000111"), ""},
+		{NewSBPDestination("+79000000000", "100000000111"), "payout_destination_data.phone"},
+		{NewSBPDestination("79000000000", ""), "payout_destination_data.bank_id"},
+		{&PayoutDestination{}, "payout_destination_data.type"},
+		{&PayoutDestination{Type: "cash"}, "payout_destination_data.type"},
+	}
+
+	for _, tt := range tests {
+		err := tt.destination.Validate()
+		verr, _ := err.(*ValidationError)
+		if tt.parameter == "" && err != nil || tt.parameter != "" && (verr == nil || verr.Parameter != tt.parameter) {
+			t.Errorf("Validate(%+v) = %v; want parameter %q", tt.destination, err, tt.parameter)
+		}
+	}
+}
+
+func TestPayoutValidate(t *testing.T) {
+
+	amount := NewAmount(10000, "RUB")
+	zero := NewAmount(0, "RUB")
+
+	tests := []struct {
+		name   string
+		payout Payout
+		want   string
+	}{
+		{"destination", Payout{Amount: &amount, PayoutDestinationData: NewYooMoneyDestination("41001614575714")}, ""},
+		{"token", Payout{Amount: &amount, PayoutToken: "token"}, ""},
+		{"empty", Payout{}, "amount,payout_destination_data"},
+		{"zero amount", Payout{Amount: &zero, PayoutToken: "token"}, "amount.value"},
+		{"no currency", Payout{Amount: &Amount{Minor: 100}, PayoutToken: "token"}, "amount.currency"},
+		{"both", Payout{Amount: &amount, PayoutToken: "token", PayoutDestinationData: NewYooMoneyDestination("41001614575714")}, "payout_destination_data"},
+		{"invalid destination", Payout{Amount: &amount, PayoutDestinationData: NewSBPDestination("79000000000", "")}, "payout_destination_data.bank_id"},
+		{"description", Payout{Amount: &amount, PayoutToken: "token", Description: strings.Repeat("x", MaxDescriptionLength+1)}, "description"},
+	}
+
+	for _, tt := range tests {
+		err := tt.payout.Validate()
+		if got := parameters(err); got != tt.want {
+			t.Errorf("%s: Validate = %q (%v); want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestPayoutJSON(t *testing.T) {
+
+	var payout Payout
+	body := `{"id":"po-1","amount":{"value":"100.00","currency":"RUB"},"status":"canceled","payout_destination":{"type":"sbp","phone":"79000000000","bank_id":"100000000111","recipient_checked":true},"cancellation_details":{"party":"yoo_money","reason":"recipient_not_found"}}`
+	if err := json.Unmarshal([]byte(body), &payout); err != nil {
+		t.Fatal(err)
+	}
+
+	if payout.Status != PayoutStatusCanceled || payout.PayoutDestination == nil || !payout.PayoutDestination.RecipientChecked ||
+		payout.CancellationDetails == nil || payout.CancellationDetails.Reason != RecipientNotFound || payout.Amount.Minor != 10000 {
+		t.Errorf("Unmarshal = %+v", payout)
+	}
+}
@@ -1,8 +1,10 @@
 package yacheckout
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -37,16 +39,16 @@ type Customer struct {
 
 //Item struct is receipt.items object
 type Item struct {
-	Description              string  `json:"description"`
-	Quantity                 string  `json:"quantity"`
-	Amount                   Amount  `json:"amount"`
-	VatCode                  uint8   `json:"vat_code"`
-	PaymentSubject           string  `json:"payment_subject,omitempty"`
-	PaymentMode              string  `json:"payment_mode,omitempty"`
-	ProductCode              string  `json:"product_code,omitempty"`
-	CountryOfOriginCode      string  `json:"country_of_origin_code,omitempty"`
-	CustomsDeclarationNumber string  `json:"customs_declaration_number,omitempty"`
-	Excise                   float64 `json:"excise,string,omitempty"`
+	Description              string   `json:"description"`
+	Quantity                 Decimal  `json:"quantity"`
+	Amount                   Amount   `json:"amount"`
+	VatCode                  uint8    `json:"vat_code"`
+	PaymentSubject           string   `json:"payment_subject,omitempty"`
+	PaymentMode              string   `json:"payment_mode,omitempty"`
+	ProductCode              string   `json:"product_code,omitempty"`
+	CountryOfOriginCode      string   `json:"country_of_origin_code,omitempty"`
+	CustomsDeclarationNumber string   `json:"customs_declaration_number,omitempty"`
+	Excise                   *Decimal `json:"excise,omitempty"`
 }
 
 //Settlement struct is receipt.settlements object
@@ -55,21 +57,85 @@ type Settlement struct {
 	Amount Amount `json:"amount"`
 }
 
+//Total func return price of item multiplied by its quantity
+func (item *Item) Total() (Amount, error) {
+	return item.Amount.MulDecimal(item.Quantity)
+}
+
+//Total func return sum of item totals of receipt
+func (receipt *Receipt) Total(currency string) (total Amount, err error) {
+
+	total.Currency = currency
+	for i := range receipt.Items {
+		var t Amount
+		if t, err = receipt.Items[i].Total(); err != nil {
+			return
+		}
+		if total, err = total.Add(t); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
 //Receipts struct is Yandex.Checkout receipts object
 type Receipts struct {
-	Type  string    `json:"type"`
-	Items []Receipt `json:"items"`
+	Type       string    `json:"type"`
+	Items      []Receipt `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+//ReceiptsFilter struct is receipts list filter.See https://kassa.yandex.ru/developers/api#get_receipts_list
+type ReceiptsFilter struct {
+	CreatedAt TimeRange
+	PaymentID string
+	RefundID  string
+	Status    string
+	Limit     int
+	Cursor    string
+}
+
+//Query func return filter as URL query
+func (filter *ReceiptsFilter) Query() url.Values {
+
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+
+	filter.CreatedAt.encode(query, "created_at")
+
+	if filter.PaymentID != "" {
+		query.Set("payment_id", filter.PaymentID)
+	}
+
+	if filter.RefundID != "" {
+		query.Set("refund_id", filter.RefundID)
+	}
+
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+
+	encodeList(query, filter.Limit, filter.Cursor)
+	return query
 }
 
 //CreateReceipt func create receipt Yandex.Checkout
 func (checkout *Checkout) CreateReceipt(client *http.Client, V4UUID *uuid.UUID, rcpt *Receipt) (receipt *Receipt, apierr *Error, err error) {
+	return checkout.CreateReceiptContext(context.Background(), client, V4UUID, rcpt)
+}
+
+//CreateReceiptContext func create receipt Yandex.Checkout bound to ctx
+func (checkout *Checkout) CreateReceiptContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, rcpt *Receipt) (receipt *Receipt, apierr *Error, err error) {
 
 	b, err := json.Marshal(rcpt)
 	if err != nil {
 		return
 	}
 
-	b, apierr, err = checkout.Exec(APIEndpoint, client, http.MethodPost, V4UUID, "receipts", b)
+	b, apierr, err = checkout.exec(ctx, client, http.MethodPost, V4UUID, "receipts", b)
 	if err != nil || apierr != nil {
 		return
 	}
@@ -80,14 +146,30 @@ func (checkout *Checkout) CreateReceipt(client *http.Client, V4UUID *uuid.UUID,
 
 //GetReceipts func receives receipts Yandex.Checkout
 func (checkout *Checkout) GetReceipts(client *http.Client, refund bool, id string) (receipts *Receipts, apierr *Error, err error) {
+	return checkout.GetReceiptsContext(context.Background(), client, refund, id)
+}
 
-	method := "payment_id="
+//GetReceiptsContext func receives receipts Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetReceiptsContext(ctx context.Context, client *http.Client, refund bool, id string) (receipts *Receipts, apierr *Error, err error) {
+
+	filter := &ReceiptsFilter{PaymentID: id}
 
 	if refund {
-		method = "refund_id="
+		filter = &ReceiptsFilter{RefundID: id}
 	}
 
-	b, apierr, err := checkout.Exec(APIEndpoint, client, http.MethodGet, nil, "receipts?"+method+id, nil)
+	return checkout.ListReceiptsContext(ctx, client, filter)
+}
+
+//ListReceipts func receives receipts list Yandex.Checkout
+func (checkout *Checkout) ListReceipts(client *http.Client, filter *ReceiptsFilter) (receipts *Receipts, apierr *Error, err error) {
+	return checkout.ListReceiptsContext(context.Background(), client, filter)
+}
+
+//ListReceiptsContext func receives receipts list Yandex.Checkout bound to ctx
+func (checkout *Checkout) ListReceiptsContext(ctx context.Context, client *http.Client, filter *ReceiptsFilter) (receipts *Receipts, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, withQuery("receipts", filter.Query()), nil)
 	if err != nil || apierr != nil {
 		return
 	}
@@ -96,10 +178,61 @@ func (checkout *Checkout) GetReceipts(client *http.Client, refund bool, id strin
 	return
 }
 
+//ReceiptIterator struct iterates receipts list following next_cursor
+type ReceiptIterator struct {
+	checkout *Checkout
+	client   *http.Client
+	filter   ReceiptsFilter
+	page     []Receipt
+	pager    pager
+}
+
+//IterateReceipts func return iterator over all receipts matching filter
+func (checkout *Checkout) IterateReceipts(client *http.Client, filter *ReceiptsFilter) *ReceiptIterator {
+
+	it := &ReceiptIterator{checkout: checkout, client: client}
+	if filter != nil {
+		it.filter = *filter
+		it.pager.cursor = filter.Cursor
+	}
+
+	return it
+}
+
+//Next func advances iterator, it return false when receipts are over or on error
+func (it *ReceiptIterator) Next(ctx context.Context) bool {
+	return it.pager.next(ctx, func(ctx context.Context, cursor string) (int, string, error) {
+
+		it.filter.Cursor = cursor
+		receipts, apierr, err := it.checkout.ListReceiptsContext(ctx, it.client, &it.filter)
+		if err = joinError(apierr, err); err != nil {
+			return 0, "", err
+		}
+
+		it.page = receipts.Items
+		return len(it.page), receipts.NextCursor, nil
+	})
+}
+
+//Receipt func return current receipt
+func (it *ReceiptIterator) Receipt() *Receipt {
+	return &it.page[it.pager.index]
+}
+
+//Err func return error stopped iteration, *Error for response errors
+func (it *ReceiptIterator) Err() error {
+	return it.pager.err
+}
+
 //GetReceipt func receives receipt Yandex.Checkout
 func (checkout *Checkout) GetReceipt(client *http.Client, id string) (receipt *Receipt, apierr *Error, err error) {
+	return checkout.GetReceiptContext(context.Background(), client, id)
+}
+
+//GetReceiptContext func receives receipt Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetReceiptContext(ctx context.Context, client *http.Client, id string) (receipt *Receipt, apierr *Error, err error) {
 
-	b, apierr, err := checkout.Exec(APIEndpoint, client, http.MethodGet, nil, "receipts/"+id, nil)
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, "receipts/"+url.PathEscape(id), nil)
 	if err != nil || apierr != nil {
 		return
 	}
@@ -0,0 +1,335 @@
+package yacheckout
+
+import (
+	"strconv"
+	"strings"
+)
+
+//Limits of receipt parameters.See https://kassa.yandex.ru/developers/payments/54fz/basics
+const (
+	MaxReceiptItems             = 100
+	MaxItemDescriptionLength    = 128
+	MaxQuantityScale            = 3
+	MaxCustomerFullNameLength   = 256
+	MaxCustomsDeclarationNumber = 32
+	countryOfOriginCodeLength   = 2
+	receiptPaymentType          = "payment"
+	receiptRefundType           = "refund"
+)
+
+var (
+	paymentSubjects = map[string]bool{
+		Commodity: true, Excise: true, Job: true, Service: true, GamblingBet: true, GamblingPrize: true,
+		Lottery: true, LotteryPrize: true, IntellectualActivity: true, Paymentc: true, AgentCommission: true,
+		PropertyRight: true, NonOperatingGain: true, InsurancePremium: true, SalesTax: true, ResortFee: true,
+		Composite: true, Another: true,
+	}
+	paymentModes = map[string]bool{
+		FullPrepayment: true, PartialPrepayment: true, Advance: true, FullPayment: true,
+		PartialPayment: true, Credit: true, CreditPayment: true,
+	}
+	settlementTypes = map[string]bool{
+		Cashless: true, Prepayment: true, Postpayment: true, Consideration: true,
+	}
+)
+
+//ReceiptBuilder struct assembles receipt under 54-FZ, see Build and BuildFor
+type ReceiptBuilder struct {
+	receipt Receipt
+}
+
+//NewReceiptBuilder func return ReceiptBuilder of receipt embedded into payment or refund
+func NewReceiptBuilder() *ReceiptBuilder {
+	return &ReceiptBuilder{}
+}
+
+//NewPaymentReceiptBuilder func return ReceiptBuilder of standalone receipt of payment for CreateReceipt
+func NewPaymentReceiptBuilder(paymentID string) *ReceiptBuilder {
+	return &ReceiptBuilder{receipt: Receipt{Type: receiptPaymentType, PaymentID: paymentID, Send: true}}
+}
+
+//NewRefundReceiptBuilder func return ReceiptBuilder of standalone receipt of refund for CreateReceipt
+func NewRefundReceiptBuilder(refundID string) *ReceiptBuilder {
+	return &ReceiptBuilder{receipt: Receipt{Type: receiptRefundType, RefundID: refundID, Send: true}}
+}
+
+//Customer func sets customer, email or phone is required
+func (builder *ReceiptBuilder) Customer(customer Customer) *ReceiptBuilder {
+	builder.receipt.Customer = &customer
+	return builder
+}
+
+//Email func sets customer email
+func (builder *ReceiptBuilder) Email(email string) *ReceiptBuilder {
+	builder.customer().Email = email
+	return builder
+}
+
+//Phone func sets customer phone in ITU-T E.164 format
+func (builder *ReceiptBuilder) Phone(phone string) *ReceiptBuilder {
+	builder.customer().Phone = phone
+	return builder
+}
+
+//TaxSystem func sets tax system code such as GeneralTaxationSystem
+func (builder *ReceiptBuilder) TaxSystem(code uint8) *ReceiptBuilder {
+	builder.receipt.TaxSystemCode = code
+	return builder
+}
+
+//Item func adds item
+func (builder *ReceiptBuilder) Item(item Item) *ReceiptBuilder {
+	builder.receipt.Items = append(builder.receipt.Items, item)
+	return builder
+}
+
+//AddItem func adds item of quantity by unit price with VAT code such as VAT20
+func (builder *ReceiptBuilder) AddItem(description string, quantity Decimal, price Amount, vatCode uint8, subject, mode string) *ReceiptBuilder {
+	return builder.Item(Item{
+		Description:    description,
+		Quantity:       quantity,
+		Amount:         price,
+		VatCode:        vatCode,
+		PaymentSubject: subject,
+		PaymentMode:    mode,
+	})
+}
+
+//Settlement func adds settlement of standalone receipt
+func (builder *ReceiptBuilder) Settlement(typ string, amount Amount) *ReceiptBuilder {
+	builder.receipt.Settlements = append(builder.receipt.Settlements, Settlement{Type: typ, Amount: amount})
+	return builder
+}
+
+//Build func return receipt or ValidationErrors with every invalid parameter
+func (builder *ReceiptBuilder) Build() (*Receipt, error) {
+
+	receipt := builder.copy()
+	if err := receipt.Validate(); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+//BuildFor func return receipt checking also that items sum to amount of payment or refund
+func (builder *ReceiptBuilder) BuildFor(amount Amount) (*Receipt, error) {
+
+	receipt := builder.copy()
+
+	var errs ValidationErrors
+	errs.Add(receipt.Validate())
+	errs.Add(receipt.validateTotal(receipt.prefix(), amount))
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+func (builder *ReceiptBuilder) customer() *Customer {
+
+	if builder.receipt.Customer == nil {
+		builder.receipt.Customer = &Customer{}
+	}
+
+	return builder.receipt.Customer
+}
+
+func (builder *ReceiptBuilder) copy() *Receipt {
+
+	receipt := builder.receipt
+	receipt.Items = append([]Item(nil), receipt.Items...)
+	receipt.Settlements = append([]Settlement(nil), receipt.Settlements...)
+	if receipt.Customer != nil {
+		customer := *receipt.Customer
+		receipt.Customer = &customer
+	}
+
+	return &receipt
+}
+
+//Validate func checks receipt under 54-FZ, it return ValidationErrors.
+//Receipt with Type is checked as standalone one for CreateReceipt, otherwise as embedded into payment or refund
+func (receipt *Receipt) Validate() error {
+	return receipt.validate(receipt.prefix(), receipt.Type != "")
+}
+
+func (receipt *Receipt) prefix() string {
+
+	if receipt.Type != "" {
+		return ""
+	}
+
+	return "receipt."
+}
+
+func (receipt *Receipt) validate(prefix string, standalone bool) error {
+
+	var errs ValidationErrors
+	invalid := func(parameter, description string) {
+		errs.Add(&ValidationError{Parameter: prefix + parameter, Description: description})
+	}
+
+	switch c := receipt.Customer; {
+	case c == nil || c.Email == "" && c.Phone == "":
+		invalid("customer", "email or phone is required")
+	default:
+		if c.Email != "" && !strings.Contains(c.Email, "@") {
+			invalid("customer.email", "invalid email")
+		}
+		if c.Phone != "" && (len(c.Phone) < 11 || len(c.Phone) > 15 || !digits(c.Phone)) {
+			invalid("customer.phone", "must be in ITU-T E.164 format")
+		}
+		if c.INN != "" && (len(c.INN) != 10 && len(c.INN) != 12 || !digits(c.INN)) {
+			invalid("customer.inn", "must be 10 or 12 digits")
+		}
+		if len([]rune(c.FullName)) > MaxCustomerFullNameLength {
+			invalid("customer.full_name", "longer than "+strconv.Itoa(MaxCustomerFullNameLength)+" characters")
+		}
+	}
+
+	if receipt.TaxSystemCode != 0 && (receipt.TaxSystemCode < GeneralTaxationSystem || receipt.TaxSystemCode > PatentTaxSystem) {
+		invalid("tax_system_code", "must be from 1 to 6")
+	}
+
+	switch n := len(receipt.Items); {
+	case n == 0:
+		invalid("items", "at least one item is required")
+	case n > MaxReceiptItems:
+		invalid("items", "more than "+strconv.Itoa(MaxReceiptItems)+" items")
+	}
+
+	for i := range receipt.Items {
+		errs.Add(receipt.Items[i].validate(prefix + "items[" + strconv.Itoa(i) + "]."))
+	}
+
+	for i, settlement := range receipt.Settlements {
+		p := "settlements[" + strconv.Itoa(i) + "]."
+		if !settlementTypes[settlement.Type] {
+			invalid(p+"type", "unknown settlement type "+settlement.Type)
+		}
+		if settlement.Amount.Sign() <= 0 {
+			invalid(p+"amount", "must be positive")
+		}
+	}
+
+	if standalone {
+		switch receipt.Type {
+		case receiptPaymentType:
+			if receipt.PaymentID == "" {
+				invalid("payment_id", "required for payment receipt")
+			}
+		case receiptRefundType:
+			if receipt.RefundID == "" {
+				invalid("refund_id", "required for refund receipt")
+			}
+		default:
+			invalid("type", "must be payment or refund")
+		}
+		if !receipt.Send {
+			invalid("send", "must be true")
+		}
+		if len(receipt.Settlements) == 0 {
+			invalid("settlements", "required for standalone receipt")
+		} else if len(receipt.Items) > 0 {
+			errs.Add(receipt.validateSettlements(prefix))
+		}
+	}
+
+	return errs.Err()
+}
+
+func (item *Item) validate(prefix string) error {
+
+	var errs ValidationErrors
+	invalid := func(parameter, description string) {
+		errs.Add(&ValidationError{Parameter: prefix + parameter, Description: description})
+	}
+
+	switch n := len([]rune(item.Description)); {
+	case n == 0:
+		invalid("description", "required")
+	case n > MaxItemDescriptionLength:
+		invalid("description", "longer than "+strconv.Itoa(MaxItemDescriptionLength)+" characters")
+	}
+
+	switch {
+	case item.Quantity.Sign() <= 0:
+		invalid("quantity", "must be positive")
+	case item.Quantity.Scale() > MaxQuantityScale:
+		invalid("quantity", "more than "+strconv.Itoa(MaxQuantityScale)+" fractional digits")
+	}
+
+	switch {
+	case item.Amount.Sign() <= 0:
+		invalid("amount.value", "must be positive")
+	case item.Amount.Currency == "":
+		invalid("amount.currency", "required")
+	}
+
+	if item.VatCode < WithoutVAT || item.VatCode > VAT20120 {
+		invalid("vat_code", "must be from 1 to 6")
+	}
+
+	if item.PaymentSubject != "" && !paymentSubjects[item.PaymentSubject] {
+		invalid("payment_subject", "unknown payment subject "+item.PaymentSubject)
+	}
+
+	if item.PaymentMode != "" && !paymentModes[item.PaymentMode] {
+		invalid("payment_mode", "unknown payment mode "+item.PaymentMode)
+	}
+
+	if item.Excise != nil && item.Excise.Sign() < 0 {
+		invalid("excise", "must not be negative")
+	}
+
+	if item.CountryOfOriginCode != "" && len(item.CountryOfOriginCode) != countryOfOriginCodeLength {
+		invalid("country_of_origin_code", "must be ISO 3166 alpha-2 code")
+	}
+
+	if len(item.CustomsDeclarationNumber) > MaxCustomsDeclarationNumber {
+		invalid("customs_declaration_number", "longer than "+strconv.Itoa(MaxCustomsDeclarationNumber)+" characters")
+	}
+
+	return errs.Err()
+}
+
+//validateTotal func checks that receipt items sum to amount
+func (receipt *Receipt) validateTotal(prefix string, amount Amount) error {
+
+	total, err := receipt.Total(amount.Currency)
+	if err != nil {
+		return &ValidationError{Parameter: prefix + "items", Description: err.Error()}
+	}
+
+	if !total.Equal(amount) {
+		return &ValidationError{Parameter: prefix + "items", Description: "sum " + total.String() + " differs from amount " + amount.String()}
+	}
+
+	return nil
+}
+
+//validateSettlements func checks that settlements sum to items total
+func (receipt *Receipt) validateSettlements(prefix string) error {
+
+	currency := receipt.Settlements[0].Amount.Currency
+	settled := Amount{Currency: currency}
+	for _, settlement := range receipt.Settlements {
+		var err error
+		if settled, err = settled.Add(settlement.Amount); err != nil {
+			return &ValidationError{Parameter: prefix + "settlements", Description: err.Error()}
+		}
+	}
+
+	total, err := receipt.Total(currency)
+	if err != nil {
+		return &ValidationError{Parameter: prefix + "items", Description: err.Error()}
+	}
+
+	if !total.Equal(settled) {
+		return &ValidationError{Parameter: prefix + "settlements", Description: "sum " + settled.String() + " differs from items total " + total.String()}
+	}
+
+	return nil
+}
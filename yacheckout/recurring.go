@@ -0,0 +1,119 @@
+package yacheckout
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+//Charge outcomes of recurring payment with saved payment method
+const (
+	ChargeSucceeded ChargeOutcome = iota + 1 //payment succeeded
+	ChargePending                            //payment is pending or waiting_for_capture
+	ChargeRetry                              //payment canceled, retry later with the same method
+	ChargeDetach                             //payment method is no longer usable, ask payer for a new one
+	ChargeFailed                             //payment canceled, retrying the same charge won't help
+)
+
+//ChargeOutcome is classified result of recurring charge, see ClassifyCancellation
+type ChargeOutcome int
+
+//ChargeResult struct is result of ChargeSavedMethod.
+//Usable reports whether saved payment method may be charged again
+type ChargeResult struct {
+	Payment *Payment
+	Outcome ChargeOutcome
+	Usable  bool
+}
+
+//retryReasons is cancellation reasons of temporary failures
+var retryReasons = map[string]bool{
+	InsufficientFunds:          true,
+	IssuerUnavailable:          true,
+	PaymentMethodLimitExceeded: true,
+	GeneralDecline:             true,
+	CallIssuer:                 true,
+}
+
+//detachReasons is cancellation reasons making saved payment method unusable
+var detachReasons = map[string]bool{
+	PermissionRevoked:       true,
+	CardExpired:             true,
+	InvalidCardNumber:       true,
+	InvalidCSC:              true,
+	PaymentMethodRestricted: true,
+	CountryForbidden:        true,
+	FraudSuspected:          true,
+}
+
+//ClassifyCancellation func return outcome of payment canceled with details.
+//For example permission_revoked is ChargeDetach and insufficient_funds is ChargeRetry
+func ClassifyCancellation(details *CancellationDetails) ChargeOutcome {
+
+	switch {
+	case details == nil:
+		return ChargeFailed
+	case retryReasons[details.Reason]:
+		return ChargeRetry
+	case detachReasons[details.Reason]:
+		return ChargeDetach
+	}
+
+	return ChargeFailed
+}
+
+//ClassifyCharge func return result of recurring payment by its status and cancellation details
+func ClassifyCharge(payment *Payment) *ChargeResult {
+
+	result := &ChargeResult{Payment: payment}
+
+	switch payment.Status {
+	case Succeeded:
+		result.Outcome = ChargeSucceeded
+	case Canceled:
+		result.Outcome = ClassifyCancellation(payment.CancellationDetails)
+	default:
+		result.Outcome = ChargePending
+	}
+
+	result.Usable = result.Outcome != ChargeDetach && (payment.PaymentMethod == nil || payment.PaymentMethod.Saved)
+	return result
+}
+
+//ChargeSavedMethod func charges saved payment method methodID Yandex.Checkout
+func (checkout *Checkout) ChargeSavedMethod(client *http.Client, V4UUID *uuid.UUID, methodID string, pay *Payment) (result *ChargeResult, apierr *Error, err error) {
+	return checkout.ChargeSavedMethodContext(context.Background(), client, V4UUID, methodID, pay)
+}
+
+//ChargeSavedMethodContext func charges saved payment method methodID Yandex.Checkout bound to ctx.
+//Payment is created from pay without confirmation and classified by ClassifyCharge.
+//Recurring charge is captured at once, so pay.Capture must be true, otherwise ValidationError is returned.
+//Unknown or unsaved methodID is reported as apierr with ChargeDetach result
+func (checkout *Checkout) ChargeSavedMethodContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, methodID string, pay *Payment) (result *ChargeResult, apierr *Error, err error) {
+
+	switch {
+	case pay == nil:
+		return nil, nil, &ValidationError{Parameter: "payment", Description: "required"}
+	case !pay.Capture:
+		return nil, nil, &ValidationError{Parameter: "capture", Description: "must be true for recurring charge"}
+	}
+
+	charge := *pay
+	charge.PaymentMethodID = methodID
+	charge.PaymentToken = ""
+	charge.PaymentMethodData = nil
+	charge.Confirmation = nil
+	charge.SavePaymentMethod = false
+
+	payment, apierr, err := checkout.CreatePaymentContext(ctx, client, V4UUID, &charge)
+	if apierr != nil && apierr.Code == InvalidRequest && apierr.Parameter == "payment_method_id" {
+		result = &ChargeResult{Outcome: ChargeDetach}
+	}
+	if err != nil || apierr != nil {
+		return
+	}
+
+	result = ClassifyCharge(payment)
+	return
+}
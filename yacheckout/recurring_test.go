@@ -0,0 +1,122 @@
+package yacheckout_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout/yacheckouttest"
+)
+
+func TestClassifyCancellation(t *testing.T) {
+
+	tests := []struct {
+		details *yacheckout.CancellationDetails
+		want    yacheckout.ChargeOutcome
+	}{
+		{nil, yacheckout.ChargeFailed},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.InsufficientFunds}, yacheckout.ChargeRetry},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.IssuerUnavailable}, yacheckout.ChargeRetry},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.PaymentMethodLimitExceeded}, yacheckout.ChargeRetry},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.GeneralDecline}, yacheckout.ChargeRetry},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.CallIssuer}, yacheckout.ChargeRetry},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.PermissionRevoked}, yacheckout.ChargeDetach},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.CardExpired}, yacheckout.ChargeDetach},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.InvalidCardNumber}, yacheckout.ChargeDetach},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.FraudSuspected}, yacheckout.ChargeDetach},
+		{&yacheckout.CancellationDetails{Reason: yacheckout.CanceledByMerchant}, yacheckout.ChargeFailed},
+		{&yacheckout.CancellationDetails{Reason: "unknown_reason"}, yacheckout.ChargeFailed},
+	}
+
+	for _, tt := range tests {
+		if got := yacheckout.ClassifyCancellation(tt.details); got != tt.want {
+			t.Errorf("ClassifyCancellation(%+v) = %d; want %d", tt.details, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyCharge(t *testing.T) {
+
+	saved := &yacheckout.PaymentMethod{Type: yacheckout.BankCard, Saved: true}
+	unsaved := &yacheckout.PaymentMethod{Type: yacheckout.BankCard}
+	revoked := &yacheckout.CancellationDetails{Reason: yacheckout.PermissionRevoked}
+	funds := &yacheckout.CancellationDetails{Reason: yacheckout.InsufficientFunds}
+
+	tests := []struct {
+		name    string
+		payment *yacheckout.Payment
+		outcome yacheckout.ChargeOutcome
+		usable  bool
+	}{
+		{"succeeded", &yacheckout.Payment{Status: yacheckout.Succeeded, PaymentMethod: saved}, yacheckout.ChargeSucceeded, true},
+		{"succeeded unsaved", &yacheckout.Payment{Status: yacheckout.Succeeded, PaymentMethod: unsaved}, yacheckout.ChargeSucceeded, false},
+		{"no method", &yacheckout.Payment{Status: yacheckout.Succeeded}, yacheckout.ChargeSucceeded, true},
+		{"pending", &yacheckout.Payment{Status: yacheckout.Pending, PaymentMethod: saved}, yacheckout.ChargePending, true},
+		{"waiting", &yacheckout.Payment{Status: yacheckout.WaitingForCapture, PaymentMethod: saved}, yacheckout.ChargePending, true},
+		{"retry", &yacheckout.Payment{Status: yacheckout.Canceled, PaymentMethod: saved, CancellationDetails: funds}, yacheckout.ChargeRetry, true},
+		{"detach", &yacheckout.Payment{Status: yacheckout.Canceled, PaymentMethod: saved, CancellationDetails: revoked}, yacheckout.ChargeDetach, false},
+		{"failed", &yacheckout.Payment{Status: yacheckout.Canceled, PaymentMethod: saved}, yacheckout.ChargeFailed, true},
+	}
+
+	for _, tt := range tests {
+		result := yacheckout.ClassifyCharge(tt.payment)
+		if result.Payment != tt.payment || result.Outcome != tt.outcome || result.Usable != tt.usable {
+			t.Errorf("%s: ClassifyCharge = %+v; want outcome %d, usable %v", tt.name, result, tt.outcome, tt.usable)
+		}
+	}
+}
+
+func TestChargeSavedMethod(t *testing.T) {
+
+	srv := yacheckouttest.NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+
+	amount := yacheckout.NewAmount(10000, "RUB")
+	key := uuid.New()
+	first, apierr, err := checkout.CreatePayment(srv.Client(), &key, &yacheckout.Payment{
+		Amount:            &amount,
+		Capture:           true,
+		SavePaymentMethod: true,
+		PaymentMethodData: &yacheckout.BankCardData{},
+		Confirmation:      yacheckout.NewRedirectConfirmation("https://example.com/return", false),
+	})
+	if err != nil || apierr != nil || !srv.ConfirmPayment(first.ID) {
+		t.Fatalf("CreatePayment: %v, %v", apierr, err)
+	}
+	methodID := srv.Payment(first.ID).PaymentMethod.ID
+
+	charge := func(methodID string, pay *yacheckout.Payment) (*yacheckout.ChargeResult, *yacheckout.Error, error) {
+		key := uuid.New()
+		return checkout.ChargeSavedMethod(srv.Client(), &key, methodID, pay)
+	}
+
+	result, apierr, err := charge(methodID, &yacheckout.Payment{Amount: &amount, Capture: true})
+	if err != nil || apierr != nil || result.Outcome != yacheckout.ChargeSucceeded || !result.Usable || result.Payment.Status != yacheckout.Succeeded {
+		t.Fatalf("ChargeSavedMethod = %+v, %v, %v", result, apierr, err)
+	}
+
+	srv.Decline = func(payment *yacheckout.Payment) string { return yacheckout.InsufficientFunds }
+	if result, apierr, err = charge(methodID, &yacheckout.Payment{Amount: &amount, Capture: true}); err != nil || apierr != nil || result.Outcome != yacheckout.ChargeRetry || !result.Usable {
+		t.Fatalf("declined ChargeSavedMethod = %+v, %v, %v", result, apierr, err)
+	}
+	srv.Decline = nil
+
+	srv.RevokePaymentMethod(methodID)
+	if result, apierr, err = charge(methodID, &yacheckout.Payment{Amount: &amount, Capture: true}); err != nil || apierr != nil || result.Outcome != yacheckout.ChargeDetach || result.Usable {
+		t.Fatalf("revoked ChargeSavedMethod = %+v, %v, %v", result, apierr, err)
+	}
+
+	if result, apierr, _ = charge("unknown", &yacheckout.Payment{Amount: &amount, Capture: true}); apierr == nil || result == nil || result.Outcome != yacheckout.ChargeDetach {
+		t.Fatalf("ChargeSavedMethod of unknown method = %+v, %v", result, apierr)
+	}
+
+	if _, _, err = charge(methodID, nil); !errors.Is(err, yacheckout.ErrInvalidRequest) {
+		t.Errorf("ChargeSavedMethod of nil payment = %v", err)
+	}
+
+	if _, _, err = charge(methodID, &yacheckout.Payment{Amount: &amount}); !errors.Is(err, yacheckout.ErrInvalidRequest) {
+		t.Errorf("ChargeSavedMethod without capture = %v", err)
+	}
+}
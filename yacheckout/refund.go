@@ -1,8 +1,10 @@
 package yacheckout
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,37 +12,106 @@ import (
 
 //Refund struct is Yandex.Checkout refund object
 type Refund struct {
-	ID          string     `json:"id,omitempty"`
-	PaymentID   string     `json:"payment_id"`
-	Requestor   *Requestor `json:"requestor,omitempty"`
-	Status      string     `json:"status,omitempty"`
-	CreatedAt   *time.Time `json:"created_at,string,omitempty"`
-	Amount      *Amount    `json:"amount,omitempty"`
-	Description string     `json:"description,omitempty"`
-	Receipt     *Receipt   `json:"receipt,omitempty"`
+	ID          string         `json:"id,omitempty"`
+	PaymentID   string         `json:"payment_id"`
+	Requestor   *Requestor     `json:"requestor,omitempty"`
+	Status      string         `json:"status,omitempty"`
+	CreatedAt   *time.Time     `json:"created_at,string,omitempty"`
+	Amount      *Amount        `json:"amount,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Receipt     *Receipt       `json:"receipt,omitempty"`
+	Sources     []RefundSource `json:"sources,omitempty"`
+	Deal        *DealInfo      `json:"deal,omitempty"`
+}
+
+//Refunds struct is Yandex.Checkout refunds list object
+type Refunds struct {
+	Type       string   `json:"type"`
+	Items      []Refund `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+//RefundsFilter struct is refunds list filter.See https://kassa.yandex.ru/developers/api#get_refunds_list
+type RefundsFilter struct {
+	CreatedAt TimeRange
+	PaymentID string
+	Status    string
+	Limit     int
+	Cursor    string
+}
+
+//Query func return filter as URL query
+func (filter *RefundsFilter) Query() url.Values {
+
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+
+	filter.CreatedAt.encode(query, "created_at")
+
+	if filter.PaymentID != "" {
+		query.Set("payment_id", filter.PaymentID)
+	}
+
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+
+	encodeList(query, filter.Limit, filter.Cursor)
+	return query
 }
 
 //CreateRefund func create refund Yandex.Checkout
 func (checkout *Checkout) CreateRefund(client *http.Client, V4UUID *uuid.UUID, rfd *Refund) (refund *Refund, apierr *Error, err error) {
+	return checkout.CreateRefundContext(context.Background(), client, V4UUID, rfd)
+}
+
+//CreateRefundContext func create refund Yandex.Checkout bound to ctx.
+//*StateError is returned without request when checkout.States knows payment can't be refunded by rfd amount
+func (checkout *Checkout) CreateRefundContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, rfd *Refund) (refund *Refund, apierr *Error, err error) {
+
+	if rfd == nil {
+		err = &ValidationError{Parameter: "refund", Description: "required"}
+		return
+	}
+
+	if err = checkout.States.Check(rfd.PaymentID, RefundOperation, rfd.Amount); err != nil {
+		return
+	}
+
+	if len(rfd.Sources) > 0 && rfd.Amount != nil {
+		if err = ValidateRefundSources(rfd.Sources, *rfd.Amount); err != nil {
+			return
+		}
+	}
 
 	b, err := json.Marshal(rfd)
 	if err != nil {
 		return
 	}
 
-	b, apierr, err = checkout.Exec(APIEndpoint, client, http.MethodPost, V4UUID, "refunds", b)
+	b, apierr, err = checkout.exec(ctx, client, http.MethodPost, V4UUID, "refunds", b)
 	if err != nil || apierr != nil {
 		return
 	}
 
+	//refunded amount of payment has changed
+	checkout.States.Forget(rfd.PaymentID)
+
 	err = json.Unmarshal(b, &refund)
 	return
 }
 
 //GetRefund func receives refund information Yandex.Checkout
 func (checkout *Checkout) GetRefund(client *http.Client, id string) (refund *Refund, apierr *Error, err error) {
+	return checkout.GetRefundContext(context.Background(), client, id)
+}
 
-	b, apierr, err := checkout.Exec(APIEndpoint, client, http.MethodGet, nil, "refunds/"+id, nil)
+//GetRefundContext func receives refund information Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetRefundContext(ctx context.Context, client *http.Client, id string) (refund *Refund, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, "refunds/"+url.PathEscape(id), nil)
 	if err != nil || apierr != nil {
 		return
 	}
@@ -48,3 +119,66 @@ func (checkout *Checkout) GetRefund(client *http.Client, id string) (refund *Ref
 	err = json.Unmarshal(b, &refund)
 	return
 }
+
+//ListRefunds func receives refunds list Yandex.Checkout
+func (checkout *Checkout) ListRefunds(client *http.Client, filter *RefundsFilter) (refunds *Refunds, apierr *Error, err error) {
+	return checkout.ListRefundsContext(context.Background(), client, filter)
+}
+
+//ListRefundsContext func receives refunds list Yandex.Checkout bound to ctx
+func (checkout *Checkout) ListRefundsContext(ctx context.Context, client *http.Client, filter *RefundsFilter) (refunds *Refunds, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, withQuery("refunds", filter.Query()), nil)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &refunds)
+	return
+}
+
+//RefundIterator struct iterates refunds list following next_cursor
+type RefundIterator struct {
+	checkout *Checkout
+	client   *http.Client
+	filter   RefundsFilter
+	page     []Refund
+	pager    pager
+}
+
+//IterateRefunds func return iterator over all refunds matching filter
+func (checkout *Checkout) IterateRefunds(client *http.Client, filter *RefundsFilter) *RefundIterator {
+
+	it := &RefundIterator{checkout: checkout, client: client}
+	if filter != nil {
+		it.filter = *filter
+		it.pager.cursor = filter.Cursor
+	}
+
+	return it
+}
+
+//Next func advances iterator, it return false when refunds are over or on error
+func (it *RefundIterator) Next(ctx context.Context) bool {
+	return it.pager.next(ctx, func(ctx context.Context, cursor string) (int, string, error) {
+
+		it.filter.Cursor = cursor
+		refunds, apierr, err := it.checkout.ListRefundsContext(ctx, it.client, &it.filter)
+		if err = joinError(apierr, err); err != nil {
+			return 0, "", err
+		}
+
+		it.page = refunds.Items
+		return len(it.page), refunds.NextCursor, nil
+	})
+}
+
+//Refund func return current refund
+func (it *RefundIterator) Refund() *Refund {
+	return &it.page[it.pager.index]
+}
+
+//Err func return error stopped iteration, *Error for response errors
+func (it *RefundIterator) Err() error {
+	return it.pager.err
+}
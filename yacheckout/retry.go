@@ -0,0 +1,112 @@
+package yacheckout
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//RetryPolicy struct is retry settings of Checkout.Exec.
+//Network errors, 202 (processing), 429 and 5xx responses are retried
+//with exponential backoff, Error.RetryAfter or Retry-After header takes precedence when present.
+//See https://kassa.yandex.ru/developers/using-api/basics#idempotence
+type RetryPolicy struct {
+	MaxAttempts int           //total number of attempts including the first one
+	MinBackoff  time.Duration //delay before the second attempt
+	MaxBackoff  time.Duration //upper bound of a single delay
+	Jitter      float64       //fraction of delay randomized, from 0 to 1
+}
+
+//DefaultRetryPolicy func return RetryPolicy suitable for most integrations
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+//Backoff func return delay before the attempt following attempt, doubling is unbounded when MaxBackoff is zero.
+//Error.RetryAfter is in milliseconds
+func (policy *RetryPolicy) Backoff(attempt int, apierr *Error) time.Duration {
+
+	if apierr != nil && apierr.RetryAfter > 0 {
+		return time.Duration(apierr.RetryAfter) * time.Millisecond
+	}
+
+	d := policy.MinBackoff
+	for i := 1; i < attempt && d > 0 && d <= math.MaxInt64/2 && (policy.MaxBackoff <= 0 || d < policy.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if policy.MaxBackoff > 0 && d > policy.MaxBackoff {
+		d = policy.MaxBackoff
+	}
+
+	if policy.Jitter > 0 && d > 0 {
+		d -= time.Duration(rand.Float64() * policy.Jitter * float64(d))
+	}
+
+	return d
+}
+
+//retry func reports whether attempt should be followed by another one
+func (policy *RetryPolicy) retry(ctx context.Context, attempt, status int, err error) bool {
+
+	if policy == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	switch {
+	case status == 0:
+		return err != nil
+	case status == http.StatusAccepted, status == http.StatusTooManyRequests:
+		return true
+	default:
+		return status >= http.StatusInternalServerError
+	}
+}
+
+//wait func sleeps before the attempt following attempt or until ctx is done
+func (policy *RetryPolicy) wait(ctx context.Context, attempt int, apierr *Error) error {
+
+	timer := time.NewTimer(policy.Backoff(attempt, apierr))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+//retryAfter func return Retry-After header in milliseconds, 0 when it is absent or invalid.
+//Header is either delay in seconds or HTTP date
+func retryAfter(header http.Header, now time.Time) uint32 {
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	var d time.Duration
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		d = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	}
+
+	switch {
+	case d <= 0:
+		return 0
+	case d/time.Millisecond > math.MaxUint32:
+		return math.MaxUint32
+	}
+
+	return uint32(d / time.Millisecond)
+}
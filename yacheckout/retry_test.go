@@ -0,0 +1,163 @@
+package yacheckout
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBackoff(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		apierr  *Error
+		want    time.Duration
+	}{
+		{"first", RetryPolicy{MinBackoff: time.Second, MaxBackoff: time.Minute}, 1, nil, time.Second},
+		{"doubled", RetryPolicy{MinBackoff: time.Second, MaxBackoff: time.Minute}, 4, nil, 8 * time.Second},
+		{"bounded", RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}, 4, nil, 5 * time.Second},
+		{"unbounded", RetryPolicy{MinBackoff: time.Second}, 6, nil, 32 * time.Second},
+		{"zero", RetryPolicy{}, 3, nil, 0},
+		{"retry after", RetryPolicy{MinBackoff: time.Second}, 3, &Error{RetryAfter: 1500}, 1500 * time.Millisecond},
+		{"retry after above bound", RetryPolicy{MinBackoff: time.Second, MaxBackoff: time.Second}, 1, &Error{RetryAfter: 3000}, 3 * time.Second},
+		{"error without retry after", RetryPolicy{MinBackoff: time.Second}, 2, &Error{}, 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.Backoff(tt.attempt, tt.apierr); got != tt.want {
+			t.Errorf("%s: Backoff(%d) = %v; want %v", tt.name, tt.attempt, got, tt.want)
+		}
+	}
+
+	if d := (&RetryPolicy{MinBackoff: time.Second}).Backoff(1000, nil); d < math.MaxInt64/2 {
+		t.Errorf("unbounded Backoff(1000) = %v; want no overflow", d)
+	}
+
+	policy := RetryPolicy{MinBackoff: time.Second, MaxBackoff: time.Minute, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if d := policy.Backoff(2, nil); d <= time.Second || d > 2*time.Second {
+			t.Fatalf("Backoff with jitter = %v; want (1s, 2s]", d)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	policy := &RetryPolicy{MaxAttempts: 3}
+	network := errors.New("connection reset")
+
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		ctx     context.Context
+		attempt int
+		status  int
+		err     error
+		want    bool
+	}{
+		{"network error", policy, context.Background(), 1, 0, network, true},
+		{"processing", policy, context.Background(), 1, http.StatusAccepted, nil, true},
+		{"too many requests", policy, context.Background(), 2, http.StatusTooManyRequests, nil, true},
+		{"server error", policy, context.Background(), 1, http.StatusInternalServerError, nil, true},
+		{"bad gateway", policy, context.Background(), 1, http.StatusBadGateway, nil, true},
+		{"ok", policy, context.Background(), 1, http.StatusOK, nil, false},
+		{"bad request", policy, context.Background(), 1, http.StatusBadRequest, nil, false},
+		{"not found", policy, context.Background(), 1, http.StatusNotFound, nil, false},
+		{"attempts exhausted", policy, context.Background(), 3, http.StatusInternalServerError, nil, false},
+		{"canceled", policy, canceled, 1, http.StatusInternalServerError, nil, false},
+		{"no policy", nil, context.Background(), 1, http.StatusInternalServerError, nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.retry(tt.ctx, tt.attempt, tt.status, tt.err); got != tt.want {
+			t.Errorf("%s: retry = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  uint32
+	}{
+		{"", 0},
+		{"0", 0},
+		{"2", 2000},
+		{"120", 120000},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(3 * time.Second).Format(http.TimeFormat), 3000},
+		{now.Add(-time.Second).Format(http.TimeFormat), 0},
+		{"4294967295", math.MaxUint32},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.value != "" {
+			header.Set("Retry-After", tt.value)
+		}
+		if got := retryAfter(header, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %d; want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExecRetries(t *testing.T) {
+
+	var attempts int
+	var keys []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		keys = append(keys, r.Header.Get("Idempotence-Key"))
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"type":"error","code":"internal_server_error"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer srv.Close()
+
+	checkout := &Checkout{ShopID: 1, SecurityToken: "secret", Retry: &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}}
+	key := uuid.New()
+
+	b, apierr, err := checkout.ExecContext(context.Background(), srv.URL+"/", srv.Client(), http.MethodPost, &key, "payments", []byte(`{}`))
+	if err != nil || apierr != nil || string(b) != `{"id":"1"}` {
+		t.Fatalf("ExecContext = %s, %v, %v", b, apierr, err)
+	}
+
+	if attempts != 3 || keys[0] != key.String() || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Fatalf("attempts %d with keys %v; want 3 with %s", attempts, keys, key)
+	}
+}
+
+func TestExecRetryAfterHeader(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"type":"error","code":"too_many_requests"}`))
+	}))
+	defer srv.Close()
+
+	_, apierr, err := (&Checkout{}).ExecContext(context.Background(), srv.URL+"/", srv.Client(), http.MethodGet, nil, "me", nil)
+	if err != nil || apierr == nil || apierr.RetryAfter != 2000 {
+		t.Fatalf("ExecContext = %+v, %v; want RetryAfter 2000", apierr, err)
+	}
+}
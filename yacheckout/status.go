@@ -0,0 +1,264 @@
+package yacheckout
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+)
+
+//PaymentOperation is operation on existing payment checked by PaymentStatus.Allows
+type PaymentOperation string
+
+//Payment operations
+const (
+	CaptureOperation PaymentOperation = "capture"
+	CancelOperation  PaymentOperation = "cancel"
+	RefundOperation  PaymentOperation = "refund"
+)
+
+//Payment state errors
+var (
+	ErrOperationNotAllowed = errors.New("yacheckout: operation is not allowed in payment status")
+	ErrInvalidTransition   = errors.New("yacheckout: invalid payment status transition")
+)
+
+//paymentTransitions is legal changes of payment status.See https://kassa.yandex.ru/developers/payments/basics/payment-process#lifecycle
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	Pending:           {WaitingForCapture, Succeeded, Canceled},
+	WaitingForCapture: {Succeeded, Canceled},
+	Succeeded:         {},
+	Canceled:          {},
+}
+
+//paymentOperations is payment statuses allowing operation
+var paymentOperations = map[PaymentOperation][]PaymentStatus{
+	CaptureOperation: {WaitingForCapture},
+	CancelOperation:  {Pending, WaitingForCapture},
+	RefundOperation:  {Succeeded},
+}
+
+//Valid func reports whether status is known payment status
+func (status PaymentStatus) Valid() bool {
+	_, ok := paymentTransitions[status]
+	return ok
+}
+
+//Final func reports whether status can't change anymore
+func (status PaymentStatus) Final() bool {
+	return status == Succeeded || status == Canceled
+}
+
+//Next func return statuses payment in status may change to
+func (status PaymentStatus) Next() []PaymentStatus {
+	return append([]PaymentStatus(nil), paymentTransitions[status]...)
+}
+
+//CanTransition func reports whether payment may change from status to to
+func (status PaymentStatus) CanTransition(to PaymentStatus) bool {
+
+	for _, next := range paymentTransitions[status] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Transition func return ErrInvalidTransition unless change from status to to is legal, same status is accepted
+func (status PaymentStatus) Transition(to PaymentStatus) error {
+
+	if to != status && !status.CanTransition(to) || !to.Valid() {
+		return ErrInvalidTransition
+	}
+
+	return nil
+}
+
+//Allows func reports whether operation is allowed in status, refund additionally requires Payment.Refundable
+func (status PaymentStatus) Allows(op PaymentOperation) bool {
+
+	for _, s := range paymentOperations[op] {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+//StateError struct is operation rejected locally by status of payment.
+//errors.Is matches ErrOperationNotAllowed and ErrInvalidRequest
+type StateError struct {
+	PaymentID   string
+	Status      PaymentStatus
+	Operation   PaymentOperation
+	Description string
+}
+
+//Error func implements error interface
+func (serr *StateError) Error() string {
+	return "yacheckout: " + string(serr.Operation) + " of payment " + serr.PaymentID + " is not allowed: " + serr.Description
+}
+
+//Is func reports whether target is ErrOperationNotAllowed or ErrInvalidRequest
+func (serr *StateError) Is(target error) bool {
+
+	if target == ErrOperationNotAllowed {
+		return true
+	}
+
+	t, ok := target.(*Error)
+	return ok && t.Code == InvalidRequest
+}
+
+//Allows func reports whether operation is allowed for payment, see Check
+func (payment *Payment) Allows(op PaymentOperation) bool {
+	return payment.Check(op, nil) == nil
+}
+
+//Check func return *StateError when operation with amount isn't allowed for payment in its current state.
+//Capture requires waiting_for_capture and amount not above payment amount,
+//cancel requires pending or waiting_for_capture,
+//refund requires succeeded refundable payment and amount not above its unrefunded part
+func (payment *Payment) Check(op PaymentOperation, amount *Amount) error {
+
+	serr := &StateError{PaymentID: payment.ID, Status: payment.Status, Operation: op}
+
+	if !payment.Status.Allows(op) {
+		serr.Description = "payment is " + string(payment.Status)
+		return serr
+	}
+
+	if op == RefundOperation && !payment.Refundable {
+		serr.Description = "payment is not refundable"
+		return serr
+	}
+
+	if amount == nil || payment.Amount == nil {
+		return nil
+	}
+
+	limit := *payment.Amount
+	if op == RefundOperation && payment.RefundedAmount != nil {
+		limit, _ = limit.Sub(*payment.RefundedAmount)
+	}
+
+	if cmp, err := amount.Cmp(limit); err != nil || cmp > 0 {
+		serr.Description = "amount " + amount.String() + " exceeds " + limit.String()
+		return serr
+	}
+
+	return nil
+}
+
+//DefaultPaymentStatesSize is number of payments remembered by PaymentStates of NewCheckout
+const DefaultPaymentStatesSize = 1000
+
+//PaymentStates struct remembers last known states of recently seen payments, so operations
+//that can't succeed anymore fail fast without request. Methods of nil PaymentStates do nothing
+type PaymentStates struct {
+	mu       sync.Mutex
+	size     int
+	order    *list.List //payments, the oldest first
+	payments map[string]*list.Element
+}
+
+//NewPaymentStates func return PaymentStates remembering up to size payments, the oldest are forgotten first
+func NewPaymentStates(size int) *PaymentStates {
+	return &PaymentStates{size: size, order: list.New(), payments: map[string]*list.Element{}}
+}
+
+//Record func remembers state of payment, state older than the known one is ignored
+func (states *PaymentStates) Record(payment *Payment) {
+
+	if states == nil || payment == nil || payment.ID == "" || !payment.Status.Valid() {
+		return
+	}
+
+	known := Payment{
+		ID:             payment.ID,
+		Status:         payment.Status,
+		Amount:         payment.Amount,
+		RefundedAmount: payment.RefundedAmount,
+		Refundable:     payment.Refundable,
+	}
+
+	states.mu.Lock()
+	defer states.mu.Unlock()
+
+	if e, ok := states.payments[payment.ID]; ok {
+		if e.Value.(*Payment).Status.Transition(payment.Status) == nil {
+			e.Value = &known
+		}
+		return
+	}
+
+	states.payments[payment.ID] = states.order.PushBack(&known)
+	for states.order.Len() > states.size {
+		delete(states.payments, states.order.Remove(states.order.Front()).(*Payment).ID)
+	}
+}
+
+//Forget func removes known state of payment id
+func (states *PaymentStates) Forget(id string) {
+
+	if states == nil {
+		return
+	}
+
+	states.mu.Lock()
+	defer states.mu.Unlock()
+
+	if e, ok := states.payments[id]; ok {
+		states.order.Remove(e)
+		delete(states.payments, id)
+	}
+}
+
+//Payment func return copy of known state of payment id, nil when it is unknown
+func (states *PaymentStates) Payment(id string) *Payment {
+
+	if states == nil {
+		return nil
+	}
+
+	states.mu.Lock()
+	defer states.mu.Unlock()
+
+	e, ok := states.payments[id]
+	if !ok {
+		return nil
+	}
+
+	known := *e.Value.(*Payment)
+	return &known
+}
+
+//Check func return *StateError when operation with amount can't be allowed for payment id by its known state.
+//Payment may have changed since, so operation allowed in any status known one may change to isn't rejected.
+//Unknown payment is never rejected
+func (states *PaymentStates) Check(id string, op PaymentOperation, amount *Amount) error {
+
+	known := states.Payment(id)
+	if known == nil {
+		return nil
+	}
+
+	expected := *known
+	if !known.Status.Allows(op) {
+		for _, next := range known.Status.Next() {
+			if next.Allows(op) {
+				expected.Status, expected.Refundable = next, true
+				break
+			}
+		}
+	}
+
+	err := expected.Check(op, amount)
+	if serr, ok := err.(*StateError); ok {
+		serr.Status = known.Status
+	}
+
+	return err
+}
@@ -0,0 +1,250 @@
+package yacheckout_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout/yacheckouttest"
+)
+
+var statuses = []yacheckout.PaymentStatus{yacheckout.Pending, yacheckout.WaitingForCapture, yacheckout.Succeeded, yacheckout.Canceled}
+
+func TestPaymentStatusTransition(t *testing.T) {
+
+	//transitions[from][to] is legal change of status
+	transitions := map[yacheckout.PaymentStatus]map[yacheckout.PaymentStatus]bool{
+		yacheckout.Pending:           {yacheckout.WaitingForCapture: true, yacheckout.Succeeded: true, yacheckout.Canceled: true},
+		yacheckout.WaitingForCapture: {yacheckout.Succeeded: true, yacheckout.Canceled: true},
+		yacheckout.Succeeded:         {},
+		yacheckout.Canceled:          {},
+	}
+
+	for _, from := range statuses {
+		if !from.Valid() {
+			t.Errorf("%s isn't valid", from)
+		}
+		if final := len(transitions[from]) == 0; from.Final() != final {
+			t.Errorf("%s.Final() = %v; want %v", from, from.Final(), final)
+		}
+		if next := from.Next(); len(next) != len(transitions[from]) {
+			t.Errorf("%s.Next() = %v", from, next)
+		}
+
+		for _, to := range statuses {
+			want := transitions[from][to]
+			if got := from.CanTransition(to); got != want {
+				t.Errorf("%s.CanTransition(%s) = %v; want %v", from, to, got, want)
+			}
+			if err := from.Transition(to); (err == nil) != (want || from == to) {
+				t.Errorf("%s.Transition(%s) = %v", from, to, err)
+			}
+		}
+
+		if from.CanTransition("unknown") || from.Transition("unknown") != yacheckout.ErrInvalidTransition {
+			t.Errorf("%s changes to unknown status", from)
+		}
+	}
+
+	if unknown := yacheckout.PaymentStatus("unknown"); unknown.Valid() || unknown.Final() || len(unknown.Next()) != 0 || unknown.Transition(unknown) == nil {
+		t.Error("unknown status is valid")
+	}
+
+	next := yacheckout.Pending.Next()
+	next[0] = yacheckout.Canceled
+	if yacheckout.Pending.Next()[0] != yacheckout.WaitingForCapture {
+		t.Error("Next returned shared transitions")
+	}
+}
+
+func TestPaymentStatusAllows(t *testing.T) {
+
+	tests := []struct {
+		status                  yacheckout.PaymentStatus
+		capture, cancel, refund bool
+	}{
+		{yacheckout.Pending, false, true, false},
+		{yacheckout.WaitingForCapture, true, true, false},
+		{yacheckout.Succeeded, false, false, true},
+		{yacheckout.Canceled, false, false, false},
+		{"unknown", false, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.Allows(yacheckout.CaptureOperation); got != tt.capture {
+			t.Errorf("%s allows capture = %v", tt.status, got)
+		}
+		if got := tt.status.Allows(yacheckout.CancelOperation); got != tt.cancel {
+			t.Errorf("%s allows cancel = %v", tt.status, got)
+		}
+		if got := tt.status.Allows(yacheckout.RefundOperation); got != tt.refund {
+			t.Errorf("%s allows refund = %v", tt.status, got)
+		}
+	}
+}
+
+func TestPaymentCheck(t *testing.T) {
+
+	amount := func(minor int64) *yacheckout.Amount {
+		a := yacheckout.NewAmount(minor, "RUB")
+		return &a
+	}
+	usd := yacheckout.NewAmount(100, "USD")
+
+	tests := []struct {
+		name    string
+		payment yacheckout.Payment
+		op      yacheckout.PaymentOperation
+		amount  *yacheckout.Amount
+		ok      bool
+	}{
+		{"capture", yacheckout.Payment{Status: yacheckout.WaitingForCapture, Amount: amount(10000)}, yacheckout.CaptureOperation, nil, true},
+		{"capture part", yacheckout.Payment{Status: yacheckout.WaitingForCapture, Amount: amount(10000)}, yacheckout.CaptureOperation, amount(6000), true},
+		{"capture more", yacheckout.Payment{Status: yacheckout.WaitingForCapture, Amount: amount(10000)}, yacheckout.CaptureOperation, amount(10001), false},
+		{"capture other currency", yacheckout.Payment{Status: yacheckout.WaitingForCapture, Amount: amount(10000)}, yacheckout.CaptureOperation, &usd, false},
+		{"capture pending", yacheckout.Payment{Status: yacheckout.Pending}, yacheckout.CaptureOperation, nil, false},
+		{"cancel pending", yacheckout.Payment{Status: yacheckout.Pending}, yacheckout.CancelOperation, nil, true},
+		{"cancel succeeded", yacheckout.Payment{Status: yacheckout.Succeeded}, yacheckout.CancelOperation, nil, false},
+		{"refund", yacheckout.Payment{Status: yacheckout.Succeeded, Refundable: true, Amount: amount(10000)}, yacheckout.RefundOperation, amount(10000), true},
+		{"refund rest", yacheckout.Payment{Status: yacheckout.Succeeded, Refundable: true, Amount: amount(10000), RefundedAmount: amount(4000)}, yacheckout.RefundOperation, amount(6000), true},
+		{"refund more than rest", yacheckout.Payment{Status: yacheckout.Succeeded, Refundable: true, Amount: amount(10000), RefundedAmount: amount(4000)}, yacheckout.RefundOperation, amount(6001), false},
+		{"refund not refundable", yacheckout.Payment{Status: yacheckout.Succeeded, Amount: amount(10000)}, yacheckout.RefundOperation, amount(100), false},
+		{"refund canceled", yacheckout.Payment{Status: yacheckout.Canceled, Refundable: true}, yacheckout.RefundOperation, nil, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.payment.Check(tt.op, tt.amount)
+		if tt.ok != (err == nil) || tt.ok != tt.payment.Allows(tt.op) && tt.amount == nil {
+			t.Errorf("%s: Check = %v; want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+
+		var serr *yacheckout.StateError
+		if err != nil && (!errors.As(err, &serr) || serr.Operation != tt.op || serr.Status != tt.payment.Status ||
+			!errors.Is(err, yacheckout.ErrOperationNotAllowed) || !errors.Is(err, yacheckout.ErrInvalidRequest)) {
+			t.Errorf("%s: Check = %#v", tt.name, err)
+		}
+	}
+}
+
+func TestPaymentStates(t *testing.T) {
+
+	amount := yacheckout.NewAmount(10000, "RUB")
+	states := yacheckout.NewPaymentStates(2)
+
+	states.Record(&yacheckout.Payment{ID: "p1", Status: yacheckout.Pending, Amount: &amount})
+	if err := states.Check("p1", yacheckout.CaptureOperation, nil); err != nil {
+		t.Errorf("capture of pending payment which may be authorized = %v", err)
+	}
+	if err := states.Check("p1", yacheckout.RefundOperation, &amount); err != nil {
+		t.Errorf("refund of pending payment which may succeed = %v", err)
+	}
+
+	more := yacheckout.NewAmount(10001, "RUB")
+	if err := states.Check("p1", yacheckout.CaptureOperation, &more); !errors.Is(err, yacheckout.ErrOperationNotAllowed) {
+		t.Errorf("capture above amount = %v", err)
+	}
+
+	states.Record(&yacheckout.Payment{ID: "p1", Status: yacheckout.Canceled, Amount: &amount})
+	states.Record(&yacheckout.Payment{ID: "p1", Status: yacheckout.Pending, Amount: &amount})
+	if p := states.Payment("p1"); p == nil || p.Status != yacheckout.Canceled {
+		t.Fatalf("known payment = %+v; want canceled", p)
+	}
+
+	var serr *yacheckout.StateError
+	if err := states.Check("p1", yacheckout.CancelOperation, nil); !errors.As(err, &serr) || serr.PaymentID != "p1" || serr.Status != yacheckout.Canceled {
+		t.Errorf("cancel of canceled payment = %v", err)
+	}
+
+	if err := states.Check("unknown", yacheckout.CancelOperation, nil); err != nil {
+		t.Errorf("cancel of unknown payment = %v", err)
+	}
+
+	states.Record(&yacheckout.Payment{ID: "p2", Status: yacheckout.Succeeded})
+	states.Record(&yacheckout.Payment{ID: "p3", Status: yacheckout.Succeeded})
+	if states.Payment("p1") != nil || states.Payment("p2") == nil || states.Payment("p3") == nil {
+		t.Error("the oldest payment isn't forgotten")
+	}
+
+	states.Forget("p2")
+	states.Record(&yacheckout.Payment{ID: "p4", Status: yacheckout.Succeeded})
+	if states.Payment("p2") != nil || states.Payment("p3") == nil || states.Payment("p4") == nil {
+		t.Error("forgotten payment is remembered")
+	}
+
+	states.Record(&yacheckout.Payment{ID: "p5", Status: "unknown"})
+	if states.Payment("p5") != nil {
+		t.Error("payment in unknown status is remembered")
+	}
+
+	var disabled *yacheckout.PaymentStates
+	disabled.Record(&yacheckout.Payment{ID: "p1", Status: yacheckout.Canceled})
+	disabled.Forget("p1")
+	if disabled.Payment("p1") != nil || disabled.Check("p1", yacheckout.CancelOperation, nil) != nil {
+		t.Error("nil PaymentStates rejected operation")
+	}
+}
+
+func TestPaymentOperationsFailFast(t *testing.T) {
+
+	srv := yacheckouttest.NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+
+	payment := pendingPayment(t, srv, checkout)
+
+	//pending payment may be authorized meanwhile, so capture is left to API
+	key := uuid.New()
+	if _, apierr, err := checkout.CapturePayment(srv.Client(), &key, payment.ID, nil); err != nil || apierr == nil || apierr.Code != yacheckout.InvalidRequest {
+		t.Fatalf("capture of pending payment = %v, %v", apierr, err)
+	}
+
+	srv.ConfirmPayment(payment.ID)
+	payment, apierr, err := checkout.GetPayment(srv.Client(), payment.ID)
+	if err != nil || apierr != nil || payment.Status != yacheckout.Succeeded {
+		t.Fatalf("GetPayment = %+v, %v, %v", payment, apierr, err)
+	}
+
+	//any request sent would fail instead
+	srv.Fail(yacheckouttest.Failure{Times: 3, Status: http.StatusInternalServerError})
+
+	key = uuid.New()
+	if _, apierr, err = checkout.CapturePayment(srv.Client(), &key, payment.ID, nil); apierr != nil || !errors.Is(err, yacheckout.ErrOperationNotAllowed) {
+		t.Errorf("capture of succeeded payment = %v, %v", apierr, err)
+	}
+
+	key = uuid.New()
+	if _, apierr, err = checkout.CancelPayment(srv.Client(), &key, payment.ID); apierr != nil || !errors.Is(err, yacheckout.ErrOperationNotAllowed) {
+		t.Errorf("cancel of succeeded payment = %v, %v", apierr, err)
+	}
+
+	more := yacheckout.NewAmount(10001, "RUB")
+	key = uuid.New()
+	if _, apierr, err = checkout.CreateRefund(srv.Client(), &key, &yacheckout.Refund{PaymentID: payment.ID, Amount: &more}); apierr != nil || !errors.Is(err, yacheckout.ErrOperationNotAllowed) {
+		t.Errorf("refund above payment amount = %v, %v", apierr, err)
+	}
+
+	key = uuid.New()
+	if _, apierr, err = checkout.CreateRefund(srv.Client(), &key, nil); apierr != nil || !errors.Is(err, yacheckout.ErrInvalidRequest) {
+		t.Errorf("refund without refund = %v, %v", apierr, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, apierr, _ = checkout.GetPayment(srv.Client(), payment.ID); apierr == nil {
+			t.Fatal("injected failures were consumed by rejected operations")
+		}
+	}
+
+	half := yacheckout.NewAmount(5000, "RUB")
+	key = uuid.New()
+	if _, apierr, err = checkout.CreateRefund(srv.Client(), &key, &yacheckout.Refund{PaymentID: payment.ID, Amount: &half}); err != nil || apierr != nil {
+		t.Fatalf("refund = %v, %v", apierr, err)
+	}
+
+	key = uuid.New()
+	if _, apierr, err = checkout.CreateRefund(srv.Client(), &key, &yacheckout.Refund{PaymentID: payment.ID, Amount: &more}); err != nil || apierr == nil {
+		t.Errorf("refund after forgotten state = %v, %v; want API error", apierr, err)
+	}
+}
@@ -0,0 +1,46 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Dunning struct is schedule of retries of failed charges.
+//Intervals are delays before each retry, Reasons overrides them by cancellation reason,
+//Grace is how long subscription stays entitled after the first failed charge
+type Dunning struct {
+	Intervals []time.Duration
+	Reasons   map[string][]time.Duration
+	Grace     time.Duration
+}
+
+//DefaultDunning func return schedule retrying after 1, 3 and 5 days within 7 days of grace,
+//insufficient_funds is retried daily
+func DefaultDunning() Dunning {
+
+	day := 24 * time.Hour
+	return Dunning{
+		Intervals: []time.Duration{day, 3 * day, 5 * day},
+		Reasons: map[string][]time.Duration{
+			yacheckout.InsufficientFunds: {day, day, day, day, day, day},
+		},
+		Grace: 7 * day,
+	}
+}
+
+//Delay func return delay before retry after failed attempt (1-based) canceled with reason.
+//It return false when schedule is exhausted
+func (dunning Dunning) Delay(reason string, attempt int) (time.Duration, bool) {
+
+	intervals, ok := dunning.Reasons[reason]
+	if !ok {
+		intervals = dunning.Intervals
+	}
+
+	if attempt < 1 || attempt > len(intervals) {
+		return 0, false
+	}
+
+	return intervals[attempt-1], true
+}
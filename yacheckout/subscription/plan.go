@@ -0,0 +1,75 @@
+//Package subscription provides recurring billing on top of saved payment methods of Yandex.Checkout
+package subscription
+
+import (
+	"time"
+
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Plan struct is billing plan charging Amount every Period
+type Plan struct {
+	ID          string
+	Description string
+	Amount      yacheckout.Amount
+	Period      Period
+}
+
+//Period struct is length of billing period in calendar months and days
+type Period struct {
+	Months int
+	Days   int
+}
+
+//Monthly and Yearly are common billing periods
+var (
+	Monthly = Period{Months: 1}
+	Yearly  = Period{Months: 12}
+)
+
+//Next func return end of period starting at t, see Add
+func (period Period) Next(t time.Time) time.Time {
+	return period.Add(t, 1)
+}
+
+//Add func return end of n-th period from anchor.
+//Day of anchor is clamped to the last day of month, so monthly periods from Jan 31 end on Feb 28 and Mar 31
+func (period Period) Add(anchor time.Time, n int) time.Time {
+
+	year, month, day := anchor.Date()
+	months := int(month) - 1 + period.Months*n
+	year, month = year+months/12, time.Month(months%12+1)
+
+	if last := time.Date(year, month+1, 0, 0, 0, 0, 0, anchor.Location()).Day(); day > last {
+		day = last
+	}
+
+	hour, min, sec := anchor.Clock()
+	t := time.Date(year, month, day, hour, min, sec, anchor.Nanosecond(), anchor.Location())
+	return t.AddDate(0, 0, period.Days*n)
+}
+
+//IsZero func reports whether period is empty
+func (period Period) IsZero() bool {
+	return period.Months <= 0 && period.Days <= 0
+}
+
+//Validate func checks plan before it is stored or subscribed to
+func (plan *Plan) Validate() error {
+
+	var errs yacheckout.ValidationErrors
+
+	if plan.ID == "" {
+		errs.Add(&yacheckout.ValidationError{Parameter: "id", Description: "required"})
+	}
+
+	if plan.Amount.Sign() <= 0 {
+		errs.Add(&yacheckout.ValidationError{Parameter: "amount.value", Description: "must be positive"})
+	}
+
+	if plan.Period.IsZero() || plan.Period.Months < 0 || plan.Period.Days < 0 {
+		errs.Add(&yacheckout.ValidationError{Parameter: "period", Description: "must be positive"})
+	}
+
+	return errs.Err()
+}
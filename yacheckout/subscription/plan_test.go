@@ -0,0 +1,73 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+func TestPeriodAdd(t *testing.T) {
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		period Period
+		anchor time.Time
+		n      int
+		want   time.Time
+	}{
+		{Monthly, date(2024, time.January, 15), 1, date(2024, time.February, 15)},
+		{Monthly, date(2024, time.January, 31), 1, date(2024, time.February, 29)},
+		{Monthly, date(2023, time.January, 31), 1, date(2023, time.February, 28)},
+		{Monthly, date(2024, time.January, 31), 2, date(2024, time.March, 31)},
+		{Monthly, date(2024, time.January, 31), 3, date(2024, time.April, 30)},
+		{Monthly, date(2024, time.November, 30), 3, date(2025, time.February, 28)},
+		{Monthly, date(2024, time.January, 31), 0, date(2024, time.January, 31)},
+		{Yearly, date(2024, time.February, 29), 1, date(2025, time.February, 28)},
+		{Yearly, date(2024, time.February, 29), 4, date(2028, time.February, 29)},
+		{Period{Days: 7}, date(2024, time.December, 28), 1, date(2025, time.January, 4)},
+		{Period{Months: 1, Days: 1}, date(2024, time.January, 31), 2, date(2024, time.April, 2)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.period.Add(tt.anchor, tt.n); !got.Equal(tt.want) {
+			t.Errorf("%+v.Add(%s, %d) = %s; want %s", tt.period, tt.anchor, tt.n, got, tt.want)
+		}
+	}
+
+	if got, want := Monthly.Next(date(2024, time.January, 31)), date(2024, time.February, 29); !got.Equal(want) {
+		t.Errorf("Next = %s; want %s", got, want)
+	}
+}
+
+func TestPlanValidate(t *testing.T) {
+
+	tests := []struct {
+		plan  Plan
+		valid bool
+	}{
+		{Plan{ID: "basic", Amount: yacheckout.NewAmount(10000, "RUB"), Period: Monthly}, true},
+		{Plan{ID: "weekly", Amount: yacheckout.NewAmount(10000, "RUB"), Period: Period{Days: 7}}, true},
+		{Plan{Amount: yacheckout.NewAmount(10000, "RUB"), Period: Monthly}, false},
+		{Plan{ID: "free", Period: Monthly}, false},
+		{Plan{ID: "negative", Amount: yacheckout.NewAmount(-100, "RUB"), Period: Monthly}, false},
+		{Plan{ID: "once", Amount: yacheckout.NewAmount(10000, "RUB")}, false},
+		{Plan{ID: "back", Amount: yacheckout.NewAmount(10000, "RUB"), Period: Period{Months: 1, Days: -1}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.plan.Validate()
+		if tt.valid && err != nil || !tt.valid && !errors.Is(err, yacheckout.ErrInvalidRequest) {
+			t.Errorf("Validate(%+v) = %v; want valid %v", tt.plan, err, tt.valid)
+		}
+	}
+
+	if err := NewMemoryStore().SavePlan(context.Background(), &Plan{ID: "free", Period: Monthly}); err == nil {
+		t.Error("SavePlan of invalid plan succeeded")
+	}
+}
@@ -0,0 +1,280 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//ErrInactive is returned for operations on canceled or lapsed subscription
+var ErrInactive = errors.New("subscription: subscription is not active")
+
+//Scheduler struct charges due subscriptions of Store, call Run periodically.
+//Charges are idempotent per subscription period, attempt and payment method, so Run may be repeated after crash
+type Scheduler struct {
+	Checkout     *yacheckout.Checkout
+	Client       *http.Client
+	Store        Store
+	Dunning      Dunning
+	PendingDelay time.Duration //delay before checking pending payment again
+
+	//Now return current time, time.Now by default
+	Now func() time.Time
+	//OnCharge is called after subscription is updated by charge result
+	OnCharge func(ctx context.Context, sub *Subscription, result *yacheckout.ChargeResult)
+}
+
+//NewScheduler func return Scheduler with DefaultDunning
+func NewScheduler(checkout *yacheckout.Checkout, store Store) *Scheduler {
+	return &Scheduler{Checkout: checkout, Store: store, Dunning: DefaultDunning(), PendingDelay: 10 * time.Minute, Now: time.Now}
+}
+
+//Subscribe func creates subscription id of customer to plan charged from saved payment method from start.
+//Pass start after the first period when it is paid by the payment saving method.
+//ErrExists is returned when subscription id already exists
+func (s *Scheduler) Subscribe(ctx context.Context, id, customerID, planID, methodID string, start time.Time) (*Subscription, error) {
+
+	plan, err := s.Store.Plan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = plan.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if start.IsZero() {
+		start = now
+	}
+
+	sub := &Subscription{
+		ID:              id,
+		CustomerID:      customerID,
+		PlanID:          planID,
+		PaymentMethodID: methodID,
+		Status:          Active,
+		Anchor:          start,
+		PaidUntil:       start,
+		NextCharge:      start,
+		CreatedAt:       now,
+	}
+
+	if err := s.Store.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+//Cancel func cancels subscription id, it stays entitled until PaidUntil
+func (s *Scheduler) Cancel(ctx context.Context, id string) (*Subscription, error) {
+
+	sub, err := s.Store.Subscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.Status != Active && sub.Status != PastDue {
+		return nil, ErrInactive
+	}
+
+	now := s.now()
+	sub.Status, sub.CanceledAt = Canceled, &now
+	if err = s.Store.SaveSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+//UpdatePaymentMethod func replaces saved payment method of subscription id,
+//past due subscription is charged on the next Run
+func (s *Scheduler) UpdatePaymentMethod(ctx context.Context, id, methodID string) (*Subscription, error) {
+
+	sub, err := s.Store.Subscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.Status != Active && sub.Status != PastDue {
+		return nil, ErrInactive
+	}
+
+	sub.PaymentMethodID, sub.NeedsMethod = methodID, false
+	if sub.Status == PastDue {
+		sub.NextCharge = s.now()
+	}
+
+	if err = s.Store.SaveSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+//Run func processes all due subscriptions and return number of processed ones.
+//Processing continues after failed subscription, the first error is returned
+func (s *Scheduler) Run(ctx context.Context) (n int, err error) {
+
+	due, err := s.Store.Due(ctx, s.now())
+	if err != nil {
+		return
+	}
+
+	for _, sub := range due {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+
+		if e := s.process(ctx, sub); e != nil {
+			if err == nil {
+				err = e
+			}
+			continue
+		}
+		n++
+	}
+
+	return
+}
+
+//process func charges subscription or lapses it when grace period is over
+func (s *Scheduler) process(ctx context.Context, sub *Subscription) error {
+
+	now := s.now()
+
+	if sub.Status == PastDue && sub.PastDueSince != nil && !now.Before(sub.PastDueSince.Add(s.Dunning.Grace)) {
+		sub.Status = Lapsed
+		return s.Store.SaveSubscription(ctx, sub)
+	}
+
+	if sub.NeedsMethod {
+		return nil
+	}
+
+	plan, err := s.Store.Plan(ctx, sub.PlanID)
+	if err != nil {
+		return err
+	}
+
+	if err = plan.Validate(); err != nil {
+		return err
+	}
+
+	result, err := s.charge(ctx, sub, plan)
+	if result == nil {
+		return err
+	}
+
+	s.apply(sub, plan, result, now)
+	if err = s.Store.SaveSubscription(ctx, sub); err != nil {
+		return err
+	}
+
+	if s.OnCharge != nil {
+		s.OnCharge(ctx, sub, result)
+	}
+
+	return nil
+}
+
+//charge func charges plan amount or checks pending payment of subscription
+func (s *Scheduler) charge(ctx context.Context, sub *Subscription, plan *Plan) (result *yacheckout.ChargeResult, err error) {
+
+	var apierr *yacheckout.Error
+
+	if sub.PendingPayment != "" {
+		var payment *yacheckout.Payment
+		if payment, apierr, err = s.Checkout.GetPaymentContext(ctx, s.Client, sub.PendingPayment); err == nil && apierr == nil {
+			result = yacheckout.ClassifyCharge(payment)
+		}
+	} else {
+		amount := plan.Amount
+		key := uuid.NewSHA1(uuid.NameSpaceURL, []byte("subscription:"+sub.ID+"/"+strconv.FormatInt(sub.PaidUntil.Unix(), 10)+
+			"/"+strconv.Itoa(sub.Attempts)+"/"+sub.PaymentMethodID))
+		result, apierr, err = s.Checkout.ChargeSavedMethodContext(ctx, s.Client, &key, sub.PaymentMethodID, &yacheckout.Payment{
+			Amount:      &amount,
+			Capture:     true,
+			Description: plan.Description,
+			Metadata:    map[string]string{"subscription_id": sub.ID},
+		})
+	}
+
+	if err == nil && apierr != nil {
+		err = apierr
+	}
+
+	return
+}
+
+//apply func updates subscription by charge result at now
+func (s *Scheduler) apply(sub *Subscription, plan *Plan, result *yacheckout.ChargeResult, now time.Time) {
+
+	sub.PendingPayment = ""
+	if result.Payment != nil {
+		sub.LastPayment = result.Payment.ID
+	}
+
+	switch result.Outcome {
+	case yacheckout.ChargeSucceeded:
+		if sub.Anchor.IsZero() {
+			sub.Anchor, sub.Periods = sub.PaidUntil, 0
+		}
+		if sub.PastDueSince != nil && now.After(sub.PaidUntil) {
+			sub.Anchor, sub.Periods = now, 0
+		}
+		sub.Periods++
+		sub.PaidUntil = plan.Period.Add(sub.Anchor, sub.Periods)
+		sub.NextCharge = sub.PaidUntil
+		sub.Status, sub.Attempts, sub.PastDueSince, sub.LastReason = Active, 0, nil, ""
+		return
+	case yacheckout.ChargePending:
+		sub.PendingPayment = result.Payment.ID
+		sub.NextCharge = now.Add(s.PendingDelay)
+		return
+	}
+
+	if result.Payment != nil && result.Payment.CancellationDetails != nil {
+		sub.LastReason = result.Payment.CancellationDetails.Reason
+	}
+
+	sub.Attempts++
+	if sub.PastDueSince == nil {
+		sub.PastDueSince = &now
+	}
+	sub.Status = PastDue
+	graceEnd := sub.PastDueSince.Add(s.Dunning.Grace)
+
+	if result.Outcome != yacheckout.ChargeRetry || !result.Usable {
+		sub.NeedsMethod = true
+		sub.NextCharge = graceEnd
+		return
+	}
+
+	//exhausted schedule isn't retried, subscription lapses when grace is over
+	delay, ok := s.Dunning.Delay(sub.LastReason, sub.Attempts)
+	if !ok {
+		sub.NextCharge = graceEnd
+		return
+	}
+
+	sub.NextCharge = now.Add(delay)
+	if sub.NextCharge.After(graceEnd) {
+		sub.NextCharge = graceEnd
+	}
+}
+
+func (s *Scheduler) now() time.Time {
+
+	if s.Now == nil {
+		return time.Now()
+	}
+
+	return s.Now()
+}
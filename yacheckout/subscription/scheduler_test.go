@@ -0,0 +1,359 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout/yacheckouttest"
+)
+
+//fixture struct is scheduler charging fake server at controlled time
+type fixture struct {
+	t       *testing.T
+	srv     *yacheckouttest.Server
+	store   *MemoryStore
+	s       *Scheduler
+	now     time.Time
+	charges int //authorizations seen by srv
+	decline string
+}
+
+func newFixture(t *testing.T) *fixture {
+
+	f := &fixture{t: t, now: time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)}
+
+	f.srv = yacheckouttest.NewServer(100500, "test_secret")
+	t.Cleanup(f.srv.Close)
+	f.srv.Now = func() time.Time { return f.now }
+	f.srv.Decline = func(payment *yacheckout.Payment) string {
+		f.charges++
+		return f.decline
+	}
+
+	f.store = NewMemoryStore()
+	if err := f.store.SavePlan(context.Background(), &Plan{ID: "basic", Amount: yacheckout.NewAmount(10000, "RUB"), Period: Monthly}); err != nil {
+		t.Fatal(err)
+	}
+
+	f.s = NewScheduler(f.srv.Checkout(), f.store)
+	f.s.Client = f.srv.Client()
+	f.s.Now = func() time.Time { return f.now }
+
+	return f
+}
+
+//method func saves payment method by confirmed payment
+func (f *fixture) method() string {
+
+	amount := yacheckout.NewAmount(100, "RUB")
+	key := uuid.New()
+	payment, apierr, err := f.srv.Checkout().CreatePayment(f.srv.Client(), &key, &yacheckout.Payment{
+		Amount:            &amount,
+		Capture:           true,
+		SavePaymentMethod: true,
+		PaymentMethodData: &yacheckout.BankCardData{},
+		Confirmation:      yacheckout.NewRedirectConfirmation("https://example.com/return", false),
+	})
+	if err != nil || apierr != nil || !f.srv.ConfirmPayment(payment.ID) {
+		f.t.Fatalf("CreatePayment: %v, %v", apierr, err)
+	}
+
+	f.charges = 0
+	return f.srv.Payment(payment.ID).PaymentMethod.ID
+}
+
+func (f *fixture) subscribe(id string) *Subscription {
+
+	sub, err := f.s.Subscribe(context.Background(), id, "customer", "basic", f.method(), time.Time{})
+	if err != nil {
+		f.t.Fatalf("Subscribe: %v", err)
+	}
+
+	return sub
+}
+
+//run func runs scheduler expecting n processed subscriptions
+func (f *fixture) run(n int) {
+
+	f.t.Helper()
+	if got, err := f.s.Run(context.Background()); got != n || err != nil {
+		f.t.Fatalf("Run at %s = %d, %v; want %d", f.now, got, err, n)
+	}
+}
+
+func (f *fixture) get(id string) *Subscription {
+
+	f.t.Helper()
+	sub, err := f.store.Subscription(context.Background(), id)
+	if err != nil {
+		f.t.Fatalf("Subscription: %v", err)
+	}
+
+	return sub
+}
+
+func TestSchedulerAnchor(t *testing.T) {
+
+	f := newFixture(t)
+	start := f.now
+	f.subscribe("sub")
+
+	f.run(1)
+	sub := f.get("sub")
+	if want := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC); sub.Status != Active || !sub.PaidUntil.Equal(want) || !sub.NextCharge.Equal(want) || sub.Periods != 1 {
+		t.Fatalf("after first charge %+v; want paid until %s", sub, want)
+	}
+	f.run(0)
+
+	f.now = sub.PaidUntil
+	f.run(1)
+	sub = f.get("sub")
+	if want := time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC); !sub.PaidUntil.Equal(want) || !sub.Anchor.Equal(start) || sub.Periods != 2 {
+		t.Fatalf("after second charge %+v; want paid until %s", sub, want)
+	}
+
+	if f.charges != 2 || !sub.Entitled(f.now, f.s.Dunning.Grace) {
+		t.Errorf("charges = %d, entitled %v", f.charges, sub.Entitled(f.now, f.s.Dunning.Grace))
+	}
+}
+
+func TestSchedulerDunning(t *testing.T) {
+
+	f := newFixture(t)
+	f.subscribe("sub")
+
+	f.decline = yacheckout.InsufficientFunds
+	f.run(1)
+	sub := f.get("sub")
+	if sub.Status != PastDue || sub.Attempts != 1 || sub.LastReason != yacheckout.InsufficientFunds || !sub.NextCharge.Equal(f.now.Add(24*time.Hour)) {
+		t.Fatalf("after declined charge %+v", sub)
+	}
+	if !sub.Entitled(f.now, f.s.Dunning.Grace) {
+		t.Error("past due subscription isn't entitled during grace")
+	}
+
+	f.now = f.now.Add(time.Hour)
+	f.run(0)
+
+	f.now, f.decline = sub.NextCharge, ""
+	f.run(1)
+	sub = f.get("sub")
+	if want := f.now.AddDate(0, 1, 0); sub.Status != Active || sub.Attempts != 0 || sub.PastDueSince != nil || sub.LastReason != "" ||
+		!sub.Anchor.Equal(f.now) || sub.Periods != 1 || !sub.PaidUntil.Equal(want) {
+		t.Fatalf("after recovered charge %+v; want paid until %s", sub, want)
+	}
+
+	if f.charges != 2 {
+		t.Errorf("charges = %d; want 2", f.charges)
+	}
+}
+
+func TestSchedulerGrace(t *testing.T) {
+
+	f := newFixture(t)
+	f.s.Dunning = Dunning{Intervals: []time.Duration{time.Hour}, Grace: 72 * time.Hour}
+	f.subscribe("sub")
+	graceEnd := f.now.Add(f.s.Dunning.Grace)
+
+	f.decline = yacheckout.GeneralDecline
+	f.run(1)
+	f.now = f.now.Add(time.Hour)
+	f.run(1)
+
+	sub := f.get("sub")
+	if sub.Status != PastDue || sub.Attempts != 2 || !sub.NextCharge.Equal(graceEnd) {
+		t.Fatalf("after exhausted dunning %+v; want past due until %s", sub, graceEnd)
+	}
+
+	f.now = graceEnd.Add(-time.Minute)
+	f.run(0)
+	if !f.get("sub").Entitled(f.now, f.s.Dunning.Grace) {
+		t.Error("subscription isn't entitled before grace end")
+	}
+
+	f.now = graceEnd
+	f.run(1)
+	if sub = f.get("sub"); sub.Status != Lapsed || sub.Entitled(f.now, f.s.Dunning.Grace) {
+		t.Fatalf("after grace %+v; want lapsed", sub)
+	}
+
+	f.now = f.now.AddDate(0, 1, 0)
+	f.run(0)
+	if f.charges != 2 {
+		t.Errorf("charges = %d; want 2", f.charges)
+	}
+
+	if _, err := f.s.UpdatePaymentMethod(context.Background(), "sub", "method"); err != ErrInactive {
+		t.Errorf("UpdatePaymentMethod of lapsed subscription = %v", err)
+	}
+}
+
+func TestSchedulerDetach(t *testing.T) {
+
+	f := newFixture(t)
+	sub := f.subscribe("sub")
+	graceEnd := f.now.Add(f.s.Dunning.Grace)
+
+	f.srv.RevokePaymentMethod(sub.PaymentMethodID)
+	f.run(1)
+	if sub = f.get("sub"); sub.Status != PastDue || !sub.NeedsMethod || !sub.NextCharge.Equal(graceEnd) {
+		t.Fatalf("after revoked method %+v; want past due needing method", sub)
+	}
+
+	f.now = f.now.Add(time.Hour)
+	methodID := f.method()
+	if _, err := f.s.UpdatePaymentMethod(context.Background(), "sub", methodID); err != nil {
+		t.Fatal(err)
+	}
+
+	f.run(1)
+	if sub = f.get("sub"); sub.Status != Active || sub.NeedsMethod || sub.PaymentMethodID != methodID || sub.Periods != 1 {
+		t.Fatalf("after updated method %+v", sub)
+	}
+}
+
+func TestSchedulerPending(t *testing.T) {
+
+	f := newFixture(t)
+	sub := f.subscribe("sub")
+
+	amount := yacheckout.NewAmount(10000, "RUB")
+	key := uuid.New()
+	payment, apierr, err := f.srv.Checkout().CreatePayment(f.srv.Client(), &key, &yacheckout.Payment{
+		Amount:       &amount,
+		Capture:      true,
+		Confirmation: yacheckout.NewRedirectConfirmation("https://example.com/return", false),
+	})
+	if err != nil || apierr != nil {
+		t.Fatalf("CreatePayment: %v, %v", apierr, err)
+	}
+
+	sub.PendingPayment = payment.ID
+	if err = f.store.SaveSubscription(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+
+	f.run(1)
+	if sub = f.get("sub"); sub.Status != Active || sub.PendingPayment != payment.ID || sub.Periods != 0 || !sub.NextCharge.Equal(f.now.Add(f.s.PendingDelay)) {
+		t.Fatalf("while pending %+v", sub)
+	}
+
+	f.now = f.now.Add(time.Minute)
+	f.run(0)
+
+	f.srv.ConfirmPayment(payment.ID)
+	f.now = sub.NextCharge
+	f.run(1)
+	if sub = f.get("sub"); sub.PendingPayment != "" || sub.LastPayment != payment.ID || sub.Periods != 1 {
+		t.Fatalf("after confirmed payment %+v", sub)
+	}
+
+	if f.charges != 1 {
+		t.Errorf("charges = %d; want 1", f.charges)
+	}
+}
+
+//crashStore struct fails the first save of subscriptions as if process crashed after charge
+type crashStore struct {
+	*MemoryStore
+	lost *Subscription
+}
+
+func (store *crashStore) SaveSubscription(ctx context.Context, sub *Subscription) error {
+
+	if store.lost == nil {
+		s := *sub
+		store.lost = &s
+		return errors.New("crash")
+	}
+
+	return store.MemoryStore.SaveSubscription(ctx, sub)
+}
+
+func TestSchedulerReplay(t *testing.T) {
+
+	f := newFixture(t)
+	f.subscribe("sub")
+	store := &crashStore{MemoryStore: f.store}
+	f.s.Store = store
+
+	if n, err := f.s.Run(context.Background()); n != 0 || err == nil {
+		t.Fatalf("Run with crash = %d, %v", n, err)
+	}
+
+	if sub := f.get("sub"); sub.Periods != 0 || sub.LastPayment != "" {
+		t.Fatalf("crashed charge saved %+v", sub)
+	}
+
+	f.now = f.now.Add(time.Minute)
+	f.run(1)
+	sub := f.get("sub")
+	if sub.LastPayment != store.lost.LastPayment || sub.Periods != 1 || !sub.PaidUntil.Equal(store.lost.PaidUntil) {
+		t.Fatalf("replayed charge %+v; want %+v", sub, store.lost)
+	}
+
+	if f.charges != 1 {
+		t.Errorf("charges = %d; want 1", f.charges)
+	}
+}
+
+func TestSchedulerSubscribe(t *testing.T) {
+
+	f := newFixture(t)
+	ctx := context.Background()
+	f.subscribe("sub")
+
+	if _, err := f.s.Subscribe(ctx, "sub", "other", "basic", "method", time.Time{}); err != ErrExists {
+		t.Errorf("Subscribe of existing id = %v; want ErrExists", err)
+	}
+
+	if _, err := f.s.Subscribe(ctx, "other", "customer", "unknown", "method", time.Time{}); err != ErrNotFound {
+		t.Errorf("Subscribe to unknown plan = %v; want ErrNotFound", err)
+	}
+
+	start := f.now.AddDate(0, 1, 0)
+	sub, err := f.s.Subscribe(ctx, "trial", "customer", "basic", "method", start)
+	if err != nil || !sub.NextCharge.Equal(start) || !sub.Anchor.Equal(start) {
+		t.Fatalf("Subscribe from %s = %+v, %v", start, sub, err)
+	}
+
+	if sub, err = f.s.Cancel(ctx, "sub"); err != nil || sub.Status != Canceled {
+		t.Fatalf("Cancel = %+v, %v", sub, err)
+	}
+
+	if _, err = f.s.Cancel(ctx, "sub"); err != ErrInactive {
+		t.Errorf("repeated Cancel = %v; want ErrInactive", err)
+	}
+
+	f.run(0)
+}
+
+func TestDunningDelay(t *testing.T) {
+
+	dunning := DefaultDunning()
+	day := 24 * time.Hour
+
+	tests := []struct {
+		reason  string
+		attempt int
+		delay   time.Duration
+		ok      bool
+	}{
+		{yacheckout.GeneralDecline, 1, day, true},
+		{yacheckout.GeneralDecline, 3, 5 * day, true},
+		{yacheckout.GeneralDecline, 4, 0, false},
+		{yacheckout.InsufficientFunds, 6, day, true},
+		{yacheckout.InsufficientFunds, 7, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		if delay, ok := dunning.Delay(tt.reason, tt.attempt); delay != tt.delay || ok != tt.ok {
+			t.Errorf("Delay(%q, %d) = %s, %v; want %s, %v", tt.reason, tt.attempt, delay, ok, tt.delay, tt.ok)
+		}
+	}
+}
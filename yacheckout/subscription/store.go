@@ -0,0 +1,130 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+//Store errors
+var (
+	ErrNotFound = errors.New("subscription: not found")
+	ErrExists   = errors.New("subscription: already exists")
+)
+
+//Store interface is persistent storage of plans and subscriptions.
+//Implementations must be safe for concurrent use
+type Store interface {
+	Plan(ctx context.Context, id string) (*Plan, error)
+	SavePlan(ctx context.Context, plan *Plan) error
+	Subscription(ctx context.Context, id string) (*Subscription, error)
+	//CreateSubscription stores new subscription, ErrExists is returned when its ID is taken
+	CreateSubscription(ctx context.Context, sub *Subscription) error
+	SaveSubscription(ctx context.Context, sub *Subscription) error
+	//Due return subscriptions active or past due with NextCharge not after t
+	Due(ctx context.Context, t time.Time) ([]*Subscription, error)
+}
+
+//MemoryStore struct is in-memory Store
+type MemoryStore struct {
+	mu            sync.Mutex
+	plans         map[string]Plan
+	subscriptions map[string]Subscription
+}
+
+//NewMemoryStore func return empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{plans: map[string]Plan{}, subscriptions: map[string]Subscription{}}
+}
+
+//Plan func return copy of plan id
+func (store *MemoryStore) Plan(ctx context.Context, id string) (*Plan, error) {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	plan, ok := store.plans[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &plan, nil
+}
+
+//SavePlan func stores copy of plan, invalid plan is rejected with its ValidationErrors
+func (store *MemoryStore) SavePlan(ctx context.Context, plan *Plan) error {
+
+	if err := plan.Validate(); err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.plans[plan.ID] = *plan
+	return nil
+}
+
+//Subscription func return copy of subscription id
+func (store *MemoryStore) Subscription(ctx context.Context, id string) (*Subscription, error) {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	sub, ok := store.subscriptions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &sub, nil
+}
+
+//CreateSubscription func stores copy of new subscription
+func (store *MemoryStore) CreateSubscription(ctx context.Context, sub *Subscription) error {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.subscriptions[sub.ID]; ok {
+		return ErrExists
+	}
+
+	store.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+//SaveSubscription func stores copy of subscription
+func (store *MemoryStore) SaveSubscription(ctx context.Context, sub *Subscription) error {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+//Due func return copies of due subscriptions ordered by NextCharge
+func (store *MemoryStore) Due(ctx context.Context, t time.Time) ([]*Subscription, error) {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var due []*Subscription
+	for _, sub := range store.subscriptions {
+		if sub.due(t) {
+			s := sub
+			due = append(due, &s)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextCharge.Equal(due[j].NextCharge) {
+			return due[i].ID < due[j].ID
+		}
+		return due[i].NextCharge.Before(due[j].NextCharge)
+	})
+
+	return due, nil
+}
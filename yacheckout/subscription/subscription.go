@@ -0,0 +1,51 @@
+package subscription
+
+import "time"
+
+//Subscription statuses
+const (
+	Active   = "active"   //charged for current period
+	PastDue  = "past_due" //charge failed, retried by dunning within grace period
+	Canceled = "canceled" //canceled, not charged anymore
+	Lapsed   = "lapsed"   //dunning or grace period is over without successful charge
+)
+
+//Subscription struct is customer subscription to plan charged from saved payment method.
+//NextCharge is time of the next charge or retry, PaidUntil is end of paid period
+type Subscription struct {
+	ID              string
+	CustomerID      string
+	PlanID          string
+	PaymentMethodID string
+	Status          string
+	Anchor          time.Time //start of billing, periods end on its day of month
+	Periods         int       //number of paid periods since Anchor
+	PaidUntil       time.Time
+	NextCharge      time.Time
+	Attempts        int        //failed attempts of current period
+	PastDueSince    *time.Time //time of the first failed attempt of current period
+	NeedsMethod     bool       //payment method is unusable, see Scheduler.UpdatePaymentMethod
+	PendingPayment  string     //ID of payment waiting for final status
+	LastPayment     string
+	LastReason      string //cancellation reason of the last failed attempt
+	CreatedAt       time.Time
+	CanceledAt      *time.Time
+}
+
+//Entitled func reports whether customer has access at t, past due subscriptions keep it during grace
+func (sub *Subscription) Entitled(t time.Time, grace time.Duration) bool {
+
+	switch sub.Status {
+	case Active, Canceled:
+		return t.Before(sub.PaidUntil)
+	case PastDue:
+		return t.Before(sub.PaidUntil) || sub.PastDueSince != nil && t.Before(sub.PastDueSince.Add(grace))
+	}
+
+	return false
+}
+
+//due func reports whether subscription should be processed by scheduler at t
+func (sub *Subscription) due(t time.Time) bool {
+	return (sub.Status == Active || sub.Status == PastDue) && !sub.NextCharge.After(t)
+}
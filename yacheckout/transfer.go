@@ -0,0 +1,182 @@
+package yacheckout
+
+import "strconv"
+
+//Transfer struct is payment.transfers object of split payments.
+//Amount goes to sub-merchant AccountID less PlatformFeeAmount kept by platform
+type Transfer struct {
+	AccountID         string      `json:"account_id"`
+	Amount            *Amount     `json:"amount"`
+	Status            string      `json:"status,omitempty"`
+	PlatformFeeAmount *Amount     `json:"platform_fee_amount,omitempty"`
+	Description       string      `json:"description,omitempty"`
+	Metadata          interface{} `json:"metadata,omitempty"`
+}
+
+//RefundSource struct is refund.sources object, refunded part of transfer to AccountID
+type RefundSource struct {
+	AccountID         string  `json:"account_id"`
+	Amount            *Amount `json:"amount"`
+	PlatformFeeAmount *Amount `json:"platform_fee_amount,omitempty"`
+}
+
+//NewTransfer func return transfer of amount to account with platform fee of rate, for example 0.05
+func NewTransfer(accountID string, amount Amount, rate Decimal) (Transfer, error) {
+
+	fee, err := PlatformFee(amount, rate)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	return Transfer{AccountID: accountID, Amount: &amount, PlatformFeeAmount: &fee}, nil
+}
+
+//PlatformFee func return fee of rate from amount rounded half away from zero, rate 0.05 is 5%
+func PlatformFee(amount Amount, rate Decimal) (Amount, error) {
+	return amount.MulDecimal(rate)
+}
+
+//Net func return part of transfer received by sub-merchant
+func (transfer *Transfer) Net() (Amount, error) {
+
+	if transfer.PlatformFeeAmount == nil {
+		return *transfer.Amount, nil
+	}
+
+	return transfer.Amount.Sub(*transfer.PlatformFeeAmount)
+}
+
+//TransfersTotal func return sum of transfers amounts and sum of their platform fees
+func TransfersTotal(transfers []Transfer, currency string) (total, fee Amount, err error) {
+
+	total.Currency, fee.Currency = currency, currency
+	for i := range transfers {
+		if transfers[i].Amount != nil {
+			if total, err = total.Add(*transfers[i].Amount); err != nil {
+				return
+			}
+		}
+		if transfers[i].PlatformFeeAmount != nil {
+			if fee, err = fee.Add(*transfers[i].PlatformFeeAmount); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+//RefundSources func return sources of refund of amount spread over transfers proportionally,
+//platform fees are returned in the same proportion. Invalid transfers are reported as ValidationErrors
+func RefundSources(transfers []Transfer, amount Amount) ([]RefundSource, error) {
+
+	total, errs := checkTransfers("transfers", transfers, amount.Currency)
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	if cmp, _ := amount.Cmp(total); amount.Sign() <= 0 || cmp > 0 {
+		return nil, ErrAllocationExceedsTotal
+	}
+
+	ratios := make([]int64, len(transfers))
+	for i := range transfers {
+		ratios[i] = transfers[i].Amount.Minor
+	}
+
+	parts, err := amount.Allocate(ratios...)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []RefundSource{}
+	for i, part := range parts {
+		if part.IsZero() {
+			continue
+		}
+
+		source := RefundSource{AccountID: transfers[i].AccountID, Amount: &parts[i]}
+		if fee := transfers[i].PlatformFeeAmount; fee != nil && !fee.IsZero() {
+			returned, err := fee.MulRatio(part.Minor, transfers[i].Amount.Minor)
+			if err != nil {
+				return nil, err
+			}
+			source.PlatformFeeAmount = &returned
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+//ValidateTransfers func checks transfers of payment or capture of amount, their sum must equal amount
+func ValidateTransfers(transfers []Transfer, amount Amount) error {
+	return validateTransfers("transfers", transfers, amount)
+}
+
+//ValidateRefundSources func checks sources of refund of amount, their sum must equal amount
+func ValidateRefundSources(sources []RefundSource, amount Amount) error {
+
+	transfers := make([]Transfer, len(sources))
+	for i, source := range sources {
+		transfers[i] = Transfer{AccountID: source.AccountID, Amount: source.Amount, PlatformFeeAmount: source.PlatformFeeAmount}
+	}
+
+	return validateTransfers("sources", transfers, amount)
+}
+
+//validateTransfers func checks transfers named by prefix against amount
+func validateTransfers(prefix string, transfers []Transfer, amount Amount) error {
+
+	total, errs := checkTransfers(prefix, transfers, amount.Currency)
+	if len(errs) == 0 && len(transfers) > 0 && !total.Equal(amount) {
+		errs.Add(&ValidationError{Parameter: prefix, Description: "sum " + total.String() + " doesn't match amount " + amount.String()})
+	}
+
+	return errs.Err()
+}
+
+//checkTransfers func checks each of transfers named by prefix and return sum of their amounts in currency
+func checkTransfers(prefix string, transfers []Transfer, currency string) (total Amount, errs ValidationErrors) {
+
+	total.Currency = currency
+
+	for i, transfer := range transfers {
+		p := prefix + "[" + strconv.Itoa(i) + "]."
+
+		if transfer.AccountID == "" {
+			errs.Add(&ValidationError{Parameter: p + "account_id", Description: "required"})
+		}
+
+		switch {
+		case transfer.Amount == nil:
+			errs.Add(&ValidationError{Parameter: p + "amount", Description: "required"})
+			continue
+		case transfer.Amount.Sign() <= 0:
+			errs.Add(&ValidationError{Parameter: p + "amount.value", Description: "must be positive"})
+		case transfer.Amount.sameCurrency(total) != nil:
+			errs.Add(&ValidationError{Parameter: p + "amount.currency", Description: "must be " + currency})
+			continue
+		}
+
+		if fee := transfer.PlatformFeeAmount; fee != nil {
+			switch cmp, err := fee.Cmp(*transfer.Amount); {
+			case err != nil:
+				errs.Add(&ValidationError{Parameter: p + "platform_fee_amount.currency", Description: "must be " + currency})
+			case fee.Sign() < 0:
+				errs.Add(&ValidationError{Parameter: p + "platform_fee_amount.value", Description: "must not be negative"})
+			case cmp > 0:
+				errs.Add(&ValidationError{Parameter: p + "platform_fee_amount.value", Description: "exceeds transfer amount"})
+			}
+		}
+
+		sum, err := total.Add(*transfer.Amount)
+		if err != nil {
+			errs.Add(&ValidationError{Parameter: p + "amount.value", Description: "sum of amounts overflows"})
+			continue
+		}
+		total = sum
+	}
+
+	return
+}
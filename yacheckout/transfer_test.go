@@ -0,0 +1,149 @@
+package yacheckout
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+//transfer func return transfer of amount and fee in minor units, negative fee means no fee
+func transfer(account string, amount, fee int64, currency string) Transfer {
+
+	a := NewAmount(amount, currency)
+	t := Transfer{AccountID: account, Amount: &a}
+	if fee >= 0 {
+		f := NewAmount(fee, currency)
+		t.PlatformFeeAmount = &f
+	}
+
+	return t
+}
+
+//parameters func return parameters of ValidationErrors err joined by comma
+func parameters(err error) string {
+
+	if err == nil {
+		return ""
+	}
+
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		return "not ValidationErrors: " + err.Error()
+	}
+
+	s := make([]string, len(errs))
+	for i, verr := range errs {
+		s[i] = verr.Parameter
+	}
+
+	return strings.Join(s, ",")
+}
+
+func TestValidateTransfers(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		transfers []Transfer
+		amount    int64
+		want      string
+	}{
+		{"valid", []Transfer{transfer("1", 6000, 300, "RUB"), transfer("2", 4000, -1, "RUB")}, 10000, ""},
+		{"currency case", []Transfer{transfer("1", 10000, 0, "rub")}, 10000, ""},
+		{"none", nil, 10000, ""},
+		{"sum mismatch", []Transfer{transfer("1", 6000, 0, "RUB")}, 10000, "transfers"},
+		{"account", []Transfer{transfer("", 10000, 0, "RUB")}, 10000, "transfers[0].account_id"},
+		{"no amount", []Transfer{{AccountID: "1"}}, 10000, "transfers[0].amount"},
+		{"zero amount", []Transfer{transfer("1", 0, -1, "RUB"), transfer("2", 10000, -1, "RUB")}, 10000, "transfers[0].amount.value"},
+		{"currency", []Transfer{transfer("1", 10000, -1, "USD")}, 10000, "transfers[0].amount.currency"},
+		{"fee currency", []Transfer{{AccountID: "1", Amount: &Amount{Minor: 10000, Currency: "RUB"}, PlatformFeeAmount: &Amount{Minor: 1, Currency: "USD"}}}, 10000, "transfers[0].platform_fee_amount.currency"},
+		{"fee exceeds", []Transfer{transfer("1", 10000, 10001, "RUB")}, 10000, "transfers[0].platform_fee_amount.value"},
+		{"all errors", []Transfer{transfer("", 10000, 10001, "RUB"), {AccountID: "2"}}, 10000, "transfers[0].account_id,transfers[0].platform_fee_amount.value,transfers[1].amount"},
+		{"overflow", []Transfer{transfer("1", math.MaxInt64, -1, "RUB"), transfer("2", 1, -1, "RUB")}, 10000, "transfers[1].amount.value"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateTransfers(tt.transfers, NewAmount(tt.amount, "RUB"))
+		if got := parameters(err); got != tt.want {
+			t.Errorf("%s: ValidateTransfers = %q (%v); want %q", tt.name, got, err, tt.want)
+		}
+		if err != nil && !strings.Contains(err.Error(), "invalid request") {
+			t.Errorf("%s: ValidateTransfers error = %v", tt.name, err)
+		}
+	}
+
+	fee := NewAmount(-1, "RUB")
+	negative := []Transfer{{AccountID: "1", Amount: &Amount{Minor: 10000, Currency: "RUB"}, PlatformFeeAmount: &fee}}
+	if got := parameters(ValidateTransfers(negative, NewAmount(10000, "RUB"))); got != "transfers[0].platform_fee_amount.value" {
+		t.Errorf("ValidateTransfers of negative fee = %q", got)
+	}
+
+	sources := []RefundSource{{AccountID: "1", Amount: &Amount{Minor: 500, Currency: "RUB"}}, {Amount: &Amount{Minor: 500, Currency: "RUB"}}}
+	if got := parameters(ValidateRefundSources(sources, NewAmount(1000, "RUB"))); got != "sources[1].account_id" {
+		t.Errorf("ValidateRefundSources = %q", got)
+	}
+}
+
+func TestRefundSources(t *testing.T) {
+
+	transfers := []Transfer{transfer("1", 6000, 600, "RUB"), transfer("2", 3000, -1, "RUB"), transfer("3", 1000, 100, "RUB")}
+
+	tests := []struct {
+		amount int64
+		want   string
+		err    error
+	}{
+		{10000, "1:60.00/6.00 2:30.00 3:10.00/1.00", nil},
+		{5000, "1:30.00/3.00 2:15.00 3:5.00/0.50", nil},
+		{1, "1:0.01/0.00", nil},
+		{3, "1:0.02/0.00 2:0.01", nil},
+		{1001, "1:6.01/0.60 2:3.00 3:1.00/0.10", nil},
+		{10001, "", ErrAllocationExceedsTotal},
+		{0, "", ErrAllocationExceedsTotal},
+		{-1, "", ErrAllocationExceedsTotal},
+	}
+
+	for _, tt := range tests {
+		sources, err := RefundSources(transfers, NewAmount(tt.amount, "RUB"))
+		if err != tt.err {
+			t.Errorf("RefundSources(%d) = %v; want %v", tt.amount, err, tt.err)
+			continue
+		}
+
+		s := make([]string, len(sources))
+		for i, source := range sources {
+			s[i] = source.AccountID + ":" + source.Amount.Value()
+			if source.PlatformFeeAmount != nil {
+				s[i] += "/" + source.PlatformFeeAmount.Value()
+			}
+		}
+		if got := strings.Join(s, " "); got != tt.want {
+			t.Errorf("RefundSources(%d) = %s; want %s", tt.amount, got, tt.want)
+		}
+	}
+
+	invalid := []Transfer{transfer("1", 6000, 0, "RUB"), {AccountID: "2"}}
+	if _, err := RefundSources(invalid, NewAmount(100, "RUB")); parameters(err) != "transfers[1].amount" {
+		t.Errorf("RefundSources of invalid transfers = %v", err)
+	}
+}
+
+func TestPlatformFee(t *testing.T) {
+
+	tr, err := NewTransfer("1", NewAmount(10050, "RUB"), NewDecimal(5, 2))
+	if err != nil || tr.PlatformFeeAmount.Minor != 503 || tr.Amount.Minor != 10050 {
+		t.Fatalf("NewTransfer = %+v, %v", tr, err)
+	}
+
+	if net, err := tr.Net(); err != nil || net.Minor != 9547 {
+		t.Errorf("Net = %v, %v; want 95.47", net, err)
+	}
+
+	if _, err = NewTransfer("1", NewAmount(math.MaxInt64, "RUB"), NewDecimal(2, 0)); err != ErrOverflow {
+		t.Errorf("NewTransfer of overflowing fee = %v; want %v", err, ErrOverflow)
+	}
+
+	total, fee, err := TransfersTotal([]Transfer{tr, transfer("2", 100, -1, "RUB")}, "RUB")
+	if err != nil || total.Minor != 10150 || fee.Minor != 503 {
+		t.Errorf("TransfersTotal = %v, %v, %v", total, fee, err)
+	}
+}
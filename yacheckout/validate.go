@@ -0,0 +1,67 @@
+package yacheckout
+
+//ValidationError struct is request parameter rejected before sending.
+//Parameter is named as in Error.Parameter, errors.Is matches ErrInvalidRequest
+type ValidationError struct {
+	Parameter   string
+	Description string
+}
+
+//Error func implements error interface
+func (verr *ValidationError) Error() string {
+	return "yacheckout: invalid " + verr.Parameter + ": " + verr.Description
+}
+
+//Is func reports whether target is ErrInvalidRequest
+func (verr *ValidationError) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == InvalidRequest
+}
+
+//ValidationErrors is list of all request parameters rejected before sending.
+//errors.Is matches ErrInvalidRequest, errors.As extracts list itself
+type ValidationErrors []*ValidationError
+
+//Error func implements error interface
+func (errs ValidationErrors) Error() string {
+
+	s := "yacheckout: invalid request:"
+	for i, verr := range errs {
+		if i > 0 {
+			s += ";"
+		}
+		s += " " + verr.Parameter + ": " + verr.Description
+	}
+
+	return s
+}
+
+//Is func reports whether target is ErrInvalidRequest
+func (errs ValidationErrors) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == InvalidRequest
+}
+
+//Add func appends err to list, ValidationErrors are flattened and nil is skipped
+func (errs *ValidationErrors) Add(err error) {
+
+	switch e := err.(type) {
+	case nil:
+	case *ValidationError:
+		*errs = append(*errs, e)
+	case ValidationErrors:
+		*errs = append(*errs, e...)
+	default:
+		*errs = append(*errs, &ValidationError{Description: err.Error()})
+	}
+}
+
+//Err func return list as error or nil when it is empty
+func (errs ValidationErrors) Err() error {
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
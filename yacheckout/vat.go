@@ -0,0 +1,106 @@
+package yacheckout
+
+import (
+	"errors"
+	"sort"
+)
+
+//ErrUnknownVatCode is returned for VAT code out of WithoutVAT..VAT20120
+var ErrUnknownVatCode = errors.New("yacheckout: unknown VAT code")
+
+//vatRates is VAT rate percent by VAT code, prices include VAT so rate r is taken as r/(100+r)
+var vatRates = map[uint8]int64{
+	WithoutVAT: 0,
+	VAT0:       0,
+	VAT10:      10,
+	VAT20:      20,
+	VAT10110:   10,
+	VAT20120:   20,
+}
+
+//VatRate func return VAT rate percent of VAT code
+func VatRate(code uint8) (int64, error) {
+
+	rate, ok := vatRates[code]
+	if !ok {
+		return 0, ErrUnknownVatCode
+	}
+
+	return rate, nil
+}
+
+//VatOf func return VAT included into gross amount at VAT code, rounded half up to minor units
+func VatOf(gross Amount, code uint8) (Amount, error) {
+
+	rate, err := VatRate(code)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	if rate == 0 {
+		return Amount{Currency: gross.Currency}, nil
+	}
+
+	return gross.MulRatio(rate, 100+rate)
+}
+
+//VAT func return VAT included into item total
+func (item *Item) VAT() (Amount, error) {
+
+	total, err := item.Total()
+	if err != nil {
+		return Amount{}, err
+	}
+
+	return VatOf(total, item.VatCode)
+}
+
+//VatSummary struct is VAT of receipt items with the same VAT code
+type VatSummary struct {
+	VatCode uint8
+	Rate    int64  //percent
+	Gross   Amount //items total including VAT
+	VAT     Amount
+}
+
+//VAT func return VAT of receipt grouped by VAT code in ascending order and its total.
+//VAT of a group is taken from its gross total as fiscal receipts do,
+//so it may differ by a minor unit from the sum of Item.VAT
+func (receipt *Receipt) VAT(currency string) (summary []VatSummary, total Amount, err error) {
+
+	total.Currency = currency
+	groups := map[uint8]*VatSummary{}
+
+	for i := range receipt.Items {
+		item := &receipt.Items[i]
+		group, ok := groups[item.VatCode]
+		if !ok {
+			rate, err := VatRate(item.VatCode)
+			if err != nil {
+				return nil, Amount{}, err
+			}
+			group = &VatSummary{VatCode: item.VatCode, Rate: rate, Gross: Amount{Currency: currency}}
+			groups[item.VatCode] = group
+		}
+		gross, err := item.Total()
+		if err != nil {
+			return nil, Amount{}, err
+		}
+		if group.Gross, err = group.Gross.Add(gross); err != nil {
+			return nil, Amount{}, err
+		}
+	}
+
+	for _, group := range groups {
+		if group.VAT, err = VatOf(group.Gross, group.VatCode); err != nil {
+			return nil, Amount{}, err
+		}
+		if total, err = total.Add(group.VAT); err != nil {
+			return nil, Amount{}, err
+		}
+		summary = append(summary, *group)
+	}
+
+	sort.Slice(summary, func(i, j int) bool { return summary[i].VatCode < summary[j].VatCode })
+	return
+}
@@ -0,0 +1,91 @@
+package yacheckout
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestVatOf(t *testing.T) {
+
+	tests := []struct {
+		gross int64
+		code  uint8
+		want  int64
+		err   error
+	}{
+		{12000, VAT20, 2000, nil},
+		{12000, VAT20120, 2000, nil},
+		{11000, VAT10, 1000, nil},
+		{1000, VAT20, 167, nil},
+		{1003, VAT20, 167, nil},
+		{1004, VAT20, 167, nil},
+		{1005, VAT20, 168, nil},
+		{2997, VAT10110, 272, nil},
+		{-1000, VAT20, -167, nil},
+		{1, VAT20, 0, nil},
+		{3, VAT20, 1, nil},
+		{12000, VAT0, 0, nil},
+		{12000, WithoutVAT, 0, nil},
+		{math.MaxInt64, VAT20, math.MaxInt64 / 6, nil},
+		{12000, 0, 0, ErrUnknownVatCode},
+		{12000, 7, 0, ErrUnknownVatCode},
+	}
+
+	for _, tt := range tests {
+		vat, err := VatOf(NewAmount(tt.gross, "RUB"), tt.code)
+		if err != tt.err || err == nil && (vat.Minor != tt.want || vat.Currency != "RUB") {
+			t.Errorf("VatOf(%d, %d) = %v, %v; want %d, %v", tt.gross, tt.code, vat, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestItemVAT(t *testing.T) {
+
+	item := &Item{Amount: NewAmount(33333, "RUB"), Quantity: NewDecimal(3, 0), VatCode: VAT20}
+	if vat, err := item.VAT(); err != nil || vat.Minor != 16667 {
+		t.Errorf("VAT of 3 x 333.33 = %v, %v; want 166.67", vat, err)
+	}
+
+	item = &Item{Amount: NewAmount(math.MaxInt64, "RUB"), Quantity: NewDecimal(2, 0), VatCode: VAT20}
+	if _, err := item.VAT(); err != ErrOverflow {
+		t.Errorf("VAT of overflowing item = %v; want %v", err, ErrOverflow)
+	}
+}
+
+func TestReceiptVAT(t *testing.T) {
+
+	receipt := &Receipt{Items: []Item{
+		{Amount: NewAmount(1000, "RUB"), Quantity: NewDecimal(1, 0), VatCode: VAT20},
+		{Amount: NewAmount(500, "RUB"), Quantity: NewDecimal(1, 0), VatCode: WithoutVAT},
+		{Amount: NewAmount(1000, "RUB"), Quantity: NewDecimal(1, 0), VatCode: VAT20},
+		{Amount: NewAmount(1000, "RUB"), Quantity: NewDecimal(1, 0), VatCode: VAT20},
+		{Amount: NewAmount(2997, "RUB"), Quantity: NewDecimal(1, 0), VatCode: VAT10},
+	}}
+
+	summary, total, err := receipt.VAT("RUB")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []VatSummary{
+		{VatCode: WithoutVAT, Rate: 0, Gross: NewAmount(500, "RUB"), VAT: NewAmount(0, "RUB")},
+		{VatCode: VAT10, Rate: 10, Gross: NewAmount(2997, "RUB"), VAT: NewAmount(272, "RUB")},
+		{VatCode: VAT20, Rate: 20, Gross: NewAmount(3000, "RUB"), VAT: NewAmount(500, "RUB")},
+	}
+
+	//3 x 167 per item would be 501, group VAT is taken from 30.00 gross
+	if !reflect.DeepEqual(summary, want) || total.Minor != 772 || total.Currency != "RUB" {
+		t.Errorf("VAT = %+v, %v; want %+v, 7.72", summary, total, want)
+	}
+
+	receipt.Items = append(receipt.Items, Item{Amount: NewAmount(1000, "RUB"), Quantity: NewDecimal(1, 0), VatCode: 9})
+	if _, _, err = receipt.VAT("RUB"); err != ErrUnknownVatCode {
+		t.Errorf("VAT with unknown code = %v; want %v", err, ErrUnknownVatCode)
+	}
+
+	receipt.Items = []Item{{Amount: NewAmount(1000, "USD"), Quantity: NewDecimal(1, 0), VatCode: VAT20}}
+	if _, _, err = receipt.VAT("RUB"); err != ErrCurrencyMismatch {
+		t.Errorf("VAT of USD items = %v; want %v", err, ErrCurrencyMismatch)
+	}
+}
@@ -0,0 +1,206 @@
+package yacheckout
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+//Notification verification errors
+var (
+	ErrUntrustedAddress   = errors.New("yacheckout: notification sent from untrusted address")
+	ErrStaleNotification  = errors.New("yacheckout: notification object status differs from API")
+	ErrUnverifiableObject = errors.New("yacheckout: notification object can't be verified")
+)
+
+//NotificationNetworks is list of networks Yandex.Checkout sends notifications from.
+//See https://kassa.yandex.ru/developers/using-api/webhooks#ip
+var NotificationNetworks = []string{
+	"185.71.76.0/27",
+	"185.71.77.0/27",
+	"77.75.153.0/25",
+	"77.75.156.11/32",
+	"77.75.156.35/32",
+	"77.75.154.128/25",
+	"2a02:5180::/32",
+}
+
+//NotificationVerifier struct checks authenticity of notifications.
+//Sender address must belong to Networks, NotificationNetworks when nil.
+//X-Forwarded-For is honored only for requests coming from TrustedProxies.
+//When Checkout is set, object is re-read from API and must have the same status
+type NotificationVerifier struct {
+	Networks       []*net.IPNet
+	TrustedProxies []*net.IPNet
+	SkipAddress    bool
+	Checkout       *Checkout
+	Client         *http.Client
+}
+
+//ParseNetworks func parses CIDR list, single addresses are accepted as well
+func ParseNetworks(cidrs ...string) (networks []*net.IPNet, err error) {
+
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+
+	return
+}
+
+//defaultNetworks is parsed NotificationNetworks
+var defaultNetworks, _ = ParseNetworks(NotificationNetworks...)
+
+//Verify func checks sender address of r and object of notification
+func (verifier *NotificationVerifier) Verify(r *http.Request, notification *Notification) error {
+
+	if err := verifier.VerifyAddress(r); err != nil {
+		return err
+	}
+
+	return verifier.VerifyObject(r.Context(), notification)
+}
+
+//VerifyAddress func checks that r is sent from allowed network
+func (verifier *NotificationVerifier) VerifyAddress(r *http.Request) error {
+
+	if verifier.SkipAddress {
+		return nil
+	}
+
+	networks := verifier.Networks
+	if networks == nil {
+		networks = defaultNetworks
+	}
+
+	if ip := verifier.RemoteIP(r); ip != nil && contains(networks, ip) {
+		return nil
+	}
+
+	return ErrUntrustedAddress
+}
+
+//RemoteIP func return address of sender of r.
+//X-Forwarded-For is walked from the right while addresses belong to TrustedProxies
+func (verifier *NotificationVerifier) RemoteIP(r *http.Request) net.IP {
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !contains(verifier.TrustedProxies, ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			return nil
+		}
+		ip = hop
+		if !contains(verifier.TrustedProxies, ip) {
+			break
+		}
+	}
+
+	return ip
+}
+
+//VerifyObject func re-reads object of notification from API when Checkout is set.
+//On success Payment, Refund, Payout or Deal of notification is replaced with object read,
+//ErrStaleNotification is returned when statuses differ, ErrUnverifiableObject when object doesn't exist
+func (verifier *NotificationVerifier) VerifyObject(ctx context.Context, notification *Notification) error {
+
+	if verifier.Checkout == nil {
+		return nil
+	}
+
+	switch {
+	case notification.Payment != nil && validObjectID(notification.Payment.ID):
+		payment, apierr, err := verifier.Checkout.GetPaymentContext(ctx, verifier.Client, notification.Payment.ID)
+		if err = joinError(apierr, err); err != nil {
+			return verifyError(err)
+		}
+		if payment.Status != notification.Payment.Status {
+			return ErrStaleNotification
+		}
+		notification.Payment = payment
+	case notification.Refund != nil && validObjectID(notification.Refund.ID):
+		refund, apierr, err := verifier.Checkout.GetRefundContext(ctx, verifier.Client, notification.Refund.ID)
+		if err = joinError(apierr, err); err != nil {
+			return verifyError(err)
+		}
+		if refund.Status != notification.Refund.Status {
+			return ErrStaleNotification
+		}
+		notification.Refund = refund
+	case notification.Payout != nil && validObjectID(notification.Payout.ID):
+		payout, apierr, err := verifier.Checkout.GetPayoutContext(ctx, verifier.Client, notification.Payout.ID)
+		if err = joinError(apierr, err); err != nil {
+			return verifyError(err)
+		}
+		if payout.Status != notification.Payout.Status {
+			return ErrStaleNotification
+		}
+		notification.Payout = payout
+	case notification.Deal != nil && validObjectID(notification.Deal.ID):
+		deal, apierr, err := verifier.Checkout.GetDealContext(ctx, verifier.Client, notification.Deal.ID)
+		if err = joinError(apierr, err); err != nil {
+			return verifyError(err)
+		}
+		if deal.Status != notification.Deal.Status {
+			return ErrStaleNotification
+		}
+		notification.Deal = deal
+	default:
+		return ErrUnverifiableObject
+	}
+
+	return nil
+}
+
+func contains(networks []*net.IPNet, ip net.IP) bool {
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//verifyError func return ErrUnverifiableObject for object not found by API
+func verifyError(err error) error {
+
+	if errors.Is(err, ErrNotFound) {
+		return ErrUnverifiableObject
+	}
+
+	return err
+}
+
+//validObjectID func reports whether id of notification object may be used as path segment
+func validObjectID(id string) bool {
+	return id != "" && id != "." && id != ".."
+}
@@ -0,0 +1,133 @@
+package yacheckout
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRemoteIP(t *testing.T) {
+
+	proxies, err := ParseNetworks("10.0.0.0/8", "192.168.1.1", "fd00::/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &NotificationVerifier{TrustedProxies: proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"direct", "185.71.76.1:443", nil, "185.71.76.1"},
+		{"direct without port", "185.71.76.1", nil, "185.71.76.1"},
+		{"direct IPv6", "[2a02:5180::1]:443", nil, "2a02:5180::1"},
+		{"spoofed by untrusted sender", "203.0.113.7:443", []string{"185.71.76.1"}, "203.0.113.7"},
+		{"spoofed through trusted proxy", "10.0.0.1:443", []string{"185.71.76.1, 203.0.113.7"}, "203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:443", []string{"185.71.76.1"}, "185.71.76.1"},
+		{"trusted proxy without header", "10.0.0.1:443", nil, "10.0.0.1"},
+		{"chained proxies", "10.0.0.1:443", []string{"185.71.76.1, 192.168.1.1", "10.1.2.3"}, "185.71.76.1"},
+		{"chained IPv6 proxies", "[fd00::1]:443", []string{"2a02:5180::1, fd00::2"}, "2a02:5180::1"},
+		{"all hops trusted", "10.0.0.1:443", []string{"10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"untrusted hop in chain", "10.0.0.1:443", []string{"185.71.76.1, 203.0.113.7, 10.0.0.2"}, "203.0.113.7"},
+		{"invalid hop", "10.0.0.1:443", []string{"185.71.76.1, bogus"}, ""},
+		{"invalid remote address", "bogus", []string{"185.71.76.1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, header := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", header)
+			}
+
+			got := verifier.RemoteIP(r)
+			if tt.want == "" && got != nil || tt.want != "" && !got.Equal(net.ParseIP(tt.want)) {
+				t.Errorf("RemoteIP = %v; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAddress(t *testing.T) {
+
+	proxies, _ := ParseNetworks("10.0.0.0/8")
+
+	tests := []struct {
+		name       string
+		verifier   NotificationVerifier
+		remoteAddr string
+		forwarded  string
+		err        error
+	}{
+		{"default networks", NotificationVerifier{}, "185.71.76.1:443", "", nil},
+		{"outside default networks", NotificationVerifier{}, "203.0.113.7:443", "", ErrUntrustedAddress},
+		{"spoofed header", NotificationVerifier{}, "203.0.113.7:443", "185.71.76.1", ErrUntrustedAddress},
+		{"behind trusted proxy", NotificationVerifier{TrustedProxies: proxies}, "10.0.0.1:443", "185.71.76.1", nil},
+		{"invalid hop", NotificationVerifier{TrustedProxies: proxies}, "10.0.0.1:443", "bogus", ErrUntrustedAddress},
+		{"skipped", NotificationVerifier{SkipAddress: true}, "203.0.113.7:443", "", nil},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+
+		if err := tt.verifier.VerifyAddress(r); err != tt.err {
+			t.Errorf("%s: VerifyAddress = %v; want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestVerifyObject(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/payments/p1":
+			w.Write([]byte(`{"id":"p1","status":"succeeded"}`))
+		case "/refunds/r1":
+			w.Write([]byte(`{"id":"r1","status":"succeeded"}`))
+		case "/deals/dl-1":
+			w.Write([]byte(`{"type":"safe_deal","id":"dl-1","status":"closed"}`))
+		case "/payouts/po-1":
+			w.Write([]byte(`{"id":"po-1","status":"canceled"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","code":"not_found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	verifier := &NotificationVerifier{Checkout: NewCheckout(100500, "test_secret", "", WithBaseURL(srv.URL+"/"))}
+
+	tests := []struct {
+		name         string
+		notification *Notification
+		err          error
+	}{
+		{"payment", &Notification{Payment: &Payment{ID: "p1", Status: Succeeded}}, nil},
+		{"refund", &Notification{Refund: &Refund{ID: "r1", Status: string(Succeeded)}}, nil},
+		{"payout", &Notification{Payout: &Payout{ID: "po-1", Status: PayoutStatusCanceled}}, nil},
+		{"stale payout", &Notification{Payout: &Payout{ID: "po-1", Status: PayoutStatusSucceeded}}, ErrStaleNotification},
+		{"deal", &Notification{Deal: &Deal{ID: "dl-1", Status: Closed}}, nil},
+		{"stale deal", &Notification{Deal: &Deal{ID: "dl-1", Status: Opened}}, ErrStaleNotification},
+		{"stale payment", &Notification{Payment: &Payment{ID: "p1", Status: WaitingForCapture}}, ErrStaleNotification},
+		{"missing payment", &Notification{Payment: &Payment{ID: "p2", Status: Succeeded}}, ErrUnverifiableObject},
+		{"path in id", &Notification{Payment: &Payment{ID: "../refunds/r1", Status: Succeeded}}, ErrUnverifiableObject},
+		{"dot id", &Notification{Payment: &Payment{ID: "..", Status: Succeeded}}, ErrUnverifiableObject},
+		{"no object", &Notification{}, ErrUnverifiableObject},
+	}
+
+	for _, tt := range tests {
+		if err := verifier.VerifyObject(context.Background(), tt.notification); err != tt.err {
+			t.Errorf("%s: VerifyObject = %v; want %v", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package yacheckout
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//Wait errors
+var (
+	ErrWaitExhausted    = errors.New("yacheckout: payment didn't reach awaited status")
+	ErrUnexpectedStatus = errors.New("yacheckout: payment reached final status not awaited")
+)
+
+//WaitOptions struct is settings of WaitForPayment
+type WaitOptions struct {
+	Statuses []PaymentStatus //statuses to wait for, WaitingForCapture, Succeeded and Canceled when empty
+	Poll     *RetryPolicy    //intervals between polls, MaxAttempts limits polls when positive, DefaultWaitPolicy when nil
+	Feed     *PaymentFeed    //notifications triggering immediate poll
+}
+
+//DefaultWaitPolicy func return polling intervals from 1 to 30 seconds without limit of polls
+func DefaultWaitPolicy() *RetryPolicy {
+	return &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 30 * time.Second, Jitter: 0.2}
+}
+
+//WaitForPayment func polls payment until it reaches awaited status
+func (checkout *Checkout) WaitForPayment(client *http.Client, id string, opts *WaitOptions) (payment *Payment, apierr *Error, err error) {
+	return checkout.WaitForPaymentContext(context.Background(), client, id, opts)
+}
+
+//WaitForPaymentContext func polls payment until it reaches awaited status, ctx is done or polls are over.
+//Polls back off by opts.Poll, Error.RetryAfter of 429 and 5xx responses is honored and they are polled again.
+//Notification of payment published to opts.Feed triggers poll at once, status is always read from API.
+//Each poll is a single request, checkout.Retry is bypassed so opts.Poll alone schedules repeated requests.
+//Payment in final status not awaited is returned at once with ErrUnexpectedStatus.
+//Last received payment is returned with ctx error or ErrWaitExhausted
+func (checkout *Checkout) WaitForPaymentContext(ctx context.Context, client *http.Client, id string, opts *WaitOptions) (payment *Payment, apierr *Error, err error) {
+
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+
+	statuses := opts.Statuses
+	if len(statuses) == 0 {
+		statuses = []PaymentStatus{WaitingForCapture, Succeeded, Canceled}
+	}
+
+	policy := opts.Poll
+	if policy == nil {
+		policy = DefaultWaitPolicy()
+	}
+
+	var notified <-chan struct{}
+	if opts.Feed != nil {
+		ch, cancel := opts.Feed.subscribe(id)
+		defer cancel()
+		notified = ch
+	}
+
+	poller := checkout.With(WithRetryPolicy(nil))
+
+	var last *Payment
+	for attempt := 1; ; attempt++ {
+
+		var current *Payment
+		current, apierr, err = poller.GetPaymentContext(ctx, client, id)
+		switch {
+		case err == nil && apierr == nil:
+			last = current
+			for _, status := range statuses {
+				if current.Status == status {
+					return current, nil, nil
+				}
+			}
+			if current.Status.Final() {
+				return current, nil, ErrUnexpectedStatus
+			}
+		case ctx.Err() != nil:
+			return last, nil, ctx.Err()
+		case apierr != nil && apierr.StatusCode != http.StatusTooManyRequests && apierr.StatusCode < http.StatusInternalServerError:
+			return last, apierr, err
+		}
+
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			if err == nil && apierr == nil {
+				err = ErrWaitExhausted
+			}
+			return last, apierr, err
+		}
+
+		timer := time.NewTimer(policy.Backoff(attempt, apierr))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, nil, ctx.Err()
+		case <-timer.C:
+		case <-notified:
+			timer.Stop()
+		}
+	}
+}
+
+//PaymentFeed struct delivers payment notifications to WaitForPayment calls waiting for them.
+//Use OnNotification as NotificationHandler.OnNotification or call Publish from own handler
+type PaymentFeed struct {
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]bool
+}
+
+//NewPaymentFeed func return PaymentFeed struct
+func NewPaymentFeed() *PaymentFeed {
+	return &PaymentFeed{waiters: map[string]map[chan struct{}]bool{}}
+}
+
+//Publish func wakes up waiters of payment, it never blocks
+func (feed *PaymentFeed) Publish(payment *Payment) {
+
+	if payment == nil {
+		return
+	}
+
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	for ch := range feed.waiters[payment.ID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+//OnNotification func publishes payment of notification, it matches NotificationHandler.OnNotification
+func (feed *PaymentFeed) OnNotification(ctx context.Context, notification *Notification) error {
+	feed.Publish(notification.Payment)
+	return nil
+}
+
+//subscribe func return channel signalled on notifications of payment id and func removing it
+func (feed *PaymentFeed) subscribe(id string) (<-chan struct{}, func()) {
+
+	ch := make(chan struct{}, 1)
+
+	feed.mu.Lock()
+	if feed.waiters == nil {
+		feed.waiters = map[string]map[chan struct{}]bool{}
+	}
+	if feed.waiters[id] == nil {
+		feed.waiters[id] = map[chan struct{}]bool{}
+	}
+	feed.waiters[id][ch] = true
+	feed.mu.Unlock()
+
+	return ch, func() {
+		feed.mu.Lock()
+		defer feed.mu.Unlock()
+
+		delete(feed.waiters[id], ch)
+		if len(feed.waiters[id]) == 0 {
+			delete(feed.waiters, id)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package yacheckout_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout/yacheckouttest"
+)
+
+//poll is fast polling policy for tests
+var poll = &yacheckout.RetryPolicy{MinBackoff: 5 * time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func pendingPayment(t *testing.T, srv *yacheckouttest.Server, checkout *yacheckout.Checkout) *yacheckout.Payment {
+
+	t.Helper()
+
+	amount := yacheckout.NewAmount(10000, "RUB")
+	key := uuid.New()
+	payment, apierr, err := checkout.CreatePayment(srv.Client(), &key, &yacheckout.Payment{
+		Amount:       &amount,
+		Capture:      true,
+		Confirmation: yacheckout.NewRedirectConfirmation("https://example.com/return", false),
+	})
+	if err != nil || apierr != nil {
+		t.Fatalf("CreatePayment: %v, %v", apierr, err)
+	}
+
+	return payment
+}
+
+func TestWaitForPayment(t *testing.T) {
+
+	srv := yacheckouttest.NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+
+	payment := pendingPayment(t, srv, checkout)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		srv.ConfirmPayment(payment.ID)
+	}()
+
+	got, apierr, err := checkout.WaitForPayment(srv.Client(), payment.ID, &yacheckout.WaitOptions{Poll: poll})
+	if err != nil || apierr != nil || got.Status != yacheckout.Succeeded {
+		t.Fatalf("WaitForPayment = %+v, %v, %v", got, apierr, err)
+	}
+
+	if got, apierr, err = checkout.WaitForPayment(srv.Client(), "unknown", &yacheckout.WaitOptions{Poll: poll}); apierr == nil || apierr.Code != yacheckout.NotFound || got != nil {
+		t.Errorf("WaitForPayment of unknown payment = %+v, %v, %v", got, apierr, err)
+	}
+}
+
+func TestWaitForPaymentFeed(t *testing.T) {
+
+	srv := yacheckouttest.NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+	feed := yacheckout.NewPaymentFeed()
+
+	payment := pendingPayment(t, srv, checkout)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+				srv.ConfirmPayment(payment.ID)
+				feed.Publish(payment)
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	//the first poll would wait an hour without notification
+	got, apierr, err := checkout.WaitForPaymentContext(ctx, srv.Client(), payment.ID, &yacheckout.WaitOptions{
+		Statuses: []yacheckout.PaymentStatus{yacheckout.Succeeded},
+		Poll:     &yacheckout.RetryPolicy{MinBackoff: time.Hour, MaxBackoff: time.Hour},
+		Feed:     feed,
+	})
+	if err != nil || apierr != nil || got.Status != yacheckout.Succeeded {
+		t.Fatalf("WaitForPayment = %+v, %v, %v", got, apierr, err)
+	}
+}
+
+func TestWaitForPaymentTimeout(t *testing.T) {
+
+	srv := yacheckouttest.NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+	payment := pendingPayment(t, srv, checkout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	got, apierr, err := checkout.WaitForPaymentContext(ctx, srv.Client(), payment.ID, &yacheckout.WaitOptions{Poll: poll})
+	if !errors.Is(err, context.DeadlineExceeded) || apierr != nil || got == nil || got.Status != yacheckout.Pending {
+		t.Fatalf("WaitForPayment past deadline = %+v, %v, %v", got, apierr, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	got, apierr, err = checkout.WaitForPaymentContext(ctx, srv.Client(), payment.ID, &yacheckout.WaitOptions{Poll: poll})
+	if !errors.Is(err, context.Canceled) || apierr != nil || got == nil || got.Status != yacheckout.Pending {
+		t.Fatalf("canceled WaitForPayment = %+v, %v, %v", got, apierr, err)
+	}
+
+	got, apierr, err = checkout.WaitForPayment(srv.Client(), payment.ID, &yacheckout.WaitOptions{
+		Poll: &yacheckout.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	if err != yacheckout.ErrWaitExhausted || apierr != nil || got == nil || got.Status != yacheckout.Pending {
+		t.Fatalf("exhausted WaitForPayment = %+v, %v, %v", got, apierr, err)
+	}
+}
+
+func TestWaitForPaymentUnexpectedStatus(t *testing.T) {
+
+	srv := yacheckouttest.NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+
+	payment := pendingPayment(t, srv, checkout)
+	srv.DeclinePayment(payment.ID, yacheckout.InsufficientFunds)
+
+	got, apierr, err := checkout.WaitForPayment(srv.Client(), payment.ID, &yacheckout.WaitOptions{Statuses: []yacheckout.PaymentStatus{yacheckout.Succeeded}, Poll: poll})
+	if err != yacheckout.ErrUnexpectedStatus || apierr != nil || got == nil || got.Status != yacheckout.Canceled {
+		t.Fatalf("WaitForPayment of canceled payment = %+v, %v, %v", got, apierr, err)
+	}
+}
+
+func TestWaitForPaymentBypassesRetry(t *testing.T) {
+
+	srv := yacheckouttest.NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout(yacheckout.WithRetryPolicy(&yacheckout.RetryPolicy{MaxAttempts: 5, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+	payment := pendingPayment(t, srv, checkout)
+
+	srv.Fail(yacheckouttest.Failure{Method: http.MethodGet, Path: "payments", Status: http.StatusInternalServerError})
+
+	got, apierr, err := checkout.WaitForPayment(srv.Client(), payment.ID, &yacheckout.WaitOptions{
+		Poll: &yacheckout.RetryPolicy{MaxAttempts: 1},
+	})
+	if apierr == nil || apierr.StatusCode != http.StatusInternalServerError || got != nil {
+		t.Fatalf("WaitForPayment with single failed poll = %+v, %v, %v", got, apierr, err)
+	}
+
+	if got, _, err = checkout.GetPayment(srv.Client(), payment.ID); err != nil || got.Status != yacheckout.Pending {
+		t.Errorf("GetPayment after failed poll = %+v, %v", got, err)
+	}
+}
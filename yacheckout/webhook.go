@@ -1,8 +1,10 @@
 package yacheckout
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 
 	"github.com/google/uuid"
 )
@@ -20,15 +22,66 @@ type Webhooks struct {
 	Items []Webhook `json:"items"`
 }
 
+//WebhooksPlan struct is difference between desired and current webhooks
+type WebhooksPlan struct {
+	Create []Webhook
+	Delete []Webhook
+	Keep   []Webhook
+}
+
+//Empty func reports whether plan changes nothing
+func (plan *WebhooksPlan) Empty() bool {
+	return len(plan.Create) == 0 && len(plan.Delete) == 0
+}
+
+//PlanWebhooks func return plan turning current webhooks into desired ones.
+//Webhooks are matched by Event and URL, duplicates are deleted
+func PlanWebhooks(current, desired []Webhook) *WebhooksPlan {
+
+	type pair struct{ event, url string }
+
+	plan := &WebhooksPlan{}
+	want := map[pair]bool{}
+	for _, webhook := range desired {
+		want[pair{webhook.Event, webhook.URL}] = true
+	}
+
+	have := map[pair]bool{}
+	for _, webhook := range current {
+		p := pair{webhook.Event, webhook.URL}
+		if want[p] && !have[p] {
+			plan.Keep = append(plan.Keep, webhook)
+		} else {
+			plan.Delete = append(plan.Delete, webhook)
+		}
+		have[p] = true
+	}
+
+	for _, webhook := range desired {
+		p := pair{webhook.Event, webhook.URL}
+		if !have[p] {
+			plan.Create = append(plan.Create, Webhook{Event: webhook.Event, URL: webhook.URL})
+			have[p] = true
+		}
+	}
+
+	return plan
+}
+
 //CreateWebhook func create webhook Yandex.Checkout
 func (checkout *Checkout) CreateWebhook(client *http.Client, V4UUID *uuid.UUID, webhk *Webhook) (webhook *Webhook, apierr *Error, err error) {
+	return checkout.CreateWebhookContext(context.Background(), client, V4UUID, webhk)
+}
+
+//CreateWebhookContext func create webhook Yandex.Checkout bound to ctx
+func (checkout *Checkout) CreateWebhookContext(ctx context.Context, client *http.Client, V4UUID *uuid.UUID, webhk *Webhook) (webhook *Webhook, apierr *Error, err error) {
 
 	b, err := json.Marshal(webhk)
 	if err != nil {
 		return
 	}
 
-	b, apierr, err = checkout.Exec(APIEndpoint, client, http.MethodPost, V4UUID, "webhooks", b)
+	b, apierr, err = checkout.exec(ctx, client, http.MethodPost, V4UUID, "webhooks", b)
 	if err != nil || apierr != nil {
 		return
 	}
@@ -39,8 +92,13 @@ func (checkout *Checkout) CreateWebhook(client *http.Client, V4UUID *uuid.UUID,
 
 //GetWebhooks func receives webhooks Yandex.Checkout
 func (checkout *Checkout) GetWebhooks(client *http.Client) (webhook *Webhooks, apierr *Error, err error) {
+	return checkout.GetWebhooksContext(context.Background(), client)
+}
 
-	b, apierr, err := checkout.Exec(APIEndpoint, client, http.MethodGet, nil, "webhooks", nil)
+//GetWebhooksContext func receives webhooks Yandex.Checkout bound to ctx
+func (checkout *Checkout) GetWebhooksContext(ctx context.Context, client *http.Client) (webhook *Webhooks, apierr *Error, err error) {
+
+	b, apierr, err := checkout.exec(ctx, client, http.MethodGet, nil, "webhooks", nil)
 	if err != nil || apierr != nil {
 		return
 	}
@@ -51,7 +109,50 @@ func (checkout *Checkout) GetWebhooks(client *http.Client) (webhook *Webhooks, a
 
 //DeleteWebhook func delete webhook Yandex.Checkout
 func (checkout *Checkout) DeleteWebhook(client *http.Client, id string) (apierr *Error, err error) {
+	return checkout.DeleteWebhookContext(context.Background(), client, id)
+}
+
+//DeleteWebhookContext func delete webhook Yandex.Checkout bound to ctx
+func (checkout *Checkout) DeleteWebhookContext(ctx context.Context, client *http.Client, id string) (apierr *Error, err error) {
+
+	_, apierr, err = checkout.exec(ctx, client, http.MethodDelete, nil, "webhooks/"+url.PathEscape(id), nil)
+	return
+}
+
+//SyncWebhooks func makes webhooks Yandex.Checkout equal to desired ones, see PlanWebhooks.
+//Missing webhooks are created before stale ones are deleted, with dryRun nothing is changed.
+//Plan is returned on error as well, created webhooks have ID set
+func (checkout *Checkout) SyncWebhooks(client *http.Client, desired []Webhook, dryRun bool) (plan *WebhooksPlan, apierr *Error, err error) {
+	return checkout.SyncWebhooksContext(context.Background(), client, desired, dryRun)
+}
+
+//SyncWebhooksContext func makes webhooks Yandex.Checkout equal to desired ones bound to ctx
+func (checkout *Checkout) SyncWebhooksContext(ctx context.Context, client *http.Client, desired []Webhook, dryRun bool) (plan *WebhooksPlan, apierr *Error, err error) {
+
+	current, apierr, err := checkout.GetWebhooksContext(ctx, client)
+	if err != nil || apierr != nil {
+		return
+	}
+
+	plan = PlanWebhooks(current.Items, desired)
+	if dryRun {
+		return
+	}
+
+	for i := range plan.Create {
+		var webhook *Webhook
+		key := uuid.New()
+		if webhook, apierr, err = checkout.CreateWebhookContext(ctx, client, &key, &plan.Create[i]); err != nil || apierr != nil {
+			return
+		}
+		plan.Create[i] = *webhook
+	}
+
+	for _, webhook := range plan.Delete {
+		if apierr, err = checkout.DeleteWebhookContext(ctx, client, webhook.ID); err != nil || apierr != nil {
+			return
+		}
+	}
 
-	_, apierr, err = checkout.Exec(APIEndpoint, client, http.MethodDelete, nil, "webhooks/"+id, nil)
 	return
 }
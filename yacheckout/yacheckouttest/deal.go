@@ -0,0 +1,150 @@
+package yacheckouttest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Deal func return copy of deal stored by srv or nil
+func (srv *Server) Deal(id string) *yacheckout.Deal {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if deal, ok := srv.deals[id]; ok {
+		d := *deal
+		return &d
+	}
+
+	return nil
+}
+
+//CloseDeal func simulates closing of deal after its payouts
+func (srv *Server) CloseDeal(id string) bool {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	deal, ok := srv.deals[id]
+	if !ok || deal.Status != yacheckout.Opened {
+		return false
+	}
+
+	deal.Status = yacheckout.Closed
+	return true
+}
+
+func (srv *Server) createDeal(w http.ResponseWriter, body []byte) {
+
+	var req yacheckout.Deal
+	if !srv.decode(w, body, &req) {
+		return
+	}
+
+	switch {
+	case req.Type != yacheckout.SafeDeal:
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Unknown deal type", "type")
+		return
+	case req.FeeMoment != yacheckout.FeeMomentPaymentSucceeded && req.FeeMoment != yacheckout.FeeMomentDealClosed:
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Unknown fee moment", "fee_moment")
+		return
+	case len([]rune(req.Description)) > 128:
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Description is too long", "description")
+		return
+	}
+
+	balance := yacheckout.NewAmount(0, "RUB")
+	payoutBalance := balance
+	expires := srv.now().AddDate(0, 0, 90)
+
+	deal := &yacheckout.Deal{
+		Type:          yacheckout.SafeDeal,
+		ID:            "dl-" + uuid.New().String(),
+		FeeMoment:     req.FeeMoment,
+		Description:   req.Description,
+		Balance:       &balance,
+		PayoutBalance: &payoutBalance,
+		Status:        yacheckout.Opened,
+		CreatedAt:     srv.now(),
+		ExpiresAt:     &expires,
+		Metadata:      req.Metadata,
+		Test:          true,
+	}
+
+	srv.deals[deal.ID] = deal
+	srv.dealIDs = append(srv.dealIDs, deal.ID)
+	writeJSON(w, http.StatusOK, deal)
+}
+
+func (srv *Server) getDeal(w http.ResponseWriter, id string) {
+
+	deal, ok := srv.deals[id]
+	if !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Deal doesn't exist", "deal_id")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deal)
+}
+
+func (srv *Server) listDeals(w http.ResponseWriter, r *http.Request) {
+
+	q := r.URL.Query()
+	var items []yacheckout.Deal
+
+	for i := len(srv.dealIDs) - 1; i >= 0; i-- {
+		d := srv.deals[srv.dealIDs[i]]
+		switch {
+		case first(q, "status") != "" && d.Status != first(q, "status"),
+			first(q, "full_text_search") != "" && !strings.Contains(d.Description, first(q, "full_text_search")),
+			!matchTime(q, "created_at", d.CreatedAt),
+			!matchTime(q, "expires_at", d.ExpiresAt):
+			continue
+		}
+		items = append(items, *d)
+	}
+
+	pg, ok := paginate(q, len(items))
+	if !ok {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Invalid limit or cursor", "cursor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &yacheckout.Deals{Type: "list", Items: append([]yacheckout.Deal{}, items[pg.from:pg.to]...), NextCursor: pg.next})
+}
+
+//openDeal func return opened deal of info or writes error
+func (srv *Server) openDeal(w http.ResponseWriter, info *yacheckout.DealInfo) (*yacheckout.Deal, bool) {
+
+	deal, ok := srv.deals[info.ID]
+	if !ok || deal.Status != yacheckout.Opened {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Deal doesn't exist or is closed", "deal.id")
+		return nil, false
+	}
+
+	return deal, true
+}
+
+//moveDeal func adds balance and payout to deal balances, negative values withdraw them
+func moveDeal(deal *yacheckout.Deal, balance, payout yacheckout.Amount) {
+
+	b, _ := deal.Balance.Add(balance)
+	p, _ := deal.PayoutBalance.Add(payout)
+	deal.Balance, deal.PayoutBalance = &b, &p
+}
+
+//settlementsTotal func return sum of settlements amounts
+func settlementsTotal(settlements []yacheckout.DealSettlement, currency string) yacheckout.Amount {
+
+	total := yacheckout.NewAmount(0, currency)
+	for _, s := range settlements {
+		if s.Amount != nil {
+			total, _ = total.Add(*s.Amount)
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,11 @@
+package yacheckouttest
+
+import (
+	"net/http"
+
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+func (srv *Server) getMe(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, &yacheckout.Me{AccountID: srv.ShopID, Test: true, FiscalizationEnabled: true})
+}
@@ -0,0 +1,367 @@
+package yacheckouttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Payment func return copy of payment stored by srv or nil
+func (srv *Server) Payment(id string) *yacheckout.Payment {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if payment, ok := srv.payments[id]; ok {
+		p := *payment
+		return &p
+	}
+
+	return nil
+}
+
+//ConfirmPayment func simulates payer confirming pending payment.
+//Payment moves to waiting_for_capture, or to succeeded when created with capture,
+//or to canceled when Decline return reason
+func (srv *Server) ConfirmPayment(id string) bool {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	payment, ok := srv.payments[id]
+	if !ok || payment.Status != yacheckout.Pending {
+		return false
+	}
+
+	srv.authorize(payment)
+	return true
+}
+
+//DeclinePayment func simulates failed confirmation of pending payment with reason
+func (srv *Server) DeclinePayment(id, reason string) bool {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	payment, ok := srv.payments[id]
+	if !ok || payment.Status != yacheckout.Pending {
+		return false
+	}
+
+	srv.cancel(payment, yacheckout.PaymentNetwork, reason)
+	return true
+}
+
+//RevokePaymentMethod func simulates payer revoking saved payment method,
+//further payments with it are canceled with permission_revoked
+func (srv *Server) RevokePaymentMethod(id string) {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.revoked[id] = true
+}
+
+func (srv *Server) createPayment(w http.ResponseWriter, body []byte) {
+
+	var req yacheckout.Payment
+	if !srv.decode(w, body, &req) {
+		return
+	}
+
+	switch {
+	case req.Amount == nil || req.Amount.Sign() <= 0:
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Amount must be positive", "amount")
+		return
+	case req.Amount.Currency == "":
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Currency is required", "amount.currency")
+		return
+	case len([]rune(req.Description)) > 128:
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Description is too long", "description")
+		return
+	}
+
+	if len(req.Transfers) > 0 {
+		if err := yacheckout.ValidateTransfers(req.Transfers, *req.Amount); err != nil {
+			srv.writeValidationError(w, err)
+			return
+		}
+	}
+
+	if req.Deal != nil {
+		if _, ok := srv.openDeal(w, req.Deal); !ok {
+			return
+		}
+		if cmp, err := settlementsTotal(req.Deal.Settlements, req.Amount.Currency).Cmp(*req.Amount); err != nil || cmp > 0 {
+			srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Settlements exceed payment amount", "deal.settlements")
+			return
+		}
+	}
+
+	payment := &yacheckout.Payment{
+		ID:                uuid.New().String(),
+		Status:            yacheckout.Pending,
+		Amount:            req.Amount,
+		Description:       req.Description,
+		Recipient:         &yacheckout.Recipient{AccountID: uint32(srv.ShopID)},
+		CreatedAt:         srv.now(),
+		Test:              true,
+		Receipt:           req.Receipt,
+		SavePaymentMethod: req.SavePaymentMethod,
+		Capture:           req.Capture,
+		Metadata:          req.Metadata,
+		Transfers:         req.Transfers,
+		Deal:              req.Deal,
+		PaymentMethod:     &yacheckout.PaymentMethod{Type: yacheckout.BankCard},
+	}
+
+	if req.PaymentMethodData != nil {
+		if err := req.PaymentMethodData.Validate(); err != nil {
+			srv.writeValidationError(w, err)
+			return
+		}
+		payment.PaymentMethod = paymentMethodOf(req.PaymentMethodData)
+	}
+	payment.PaymentMethod.ID = payment.ID
+
+	if req.PaymentMethodID != "" {
+		method, ok := srv.methods[req.PaymentMethodID]
+		if !ok {
+			srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Payment method not found", "payment_method_id")
+			return
+		}
+		m := *method
+		payment.PaymentMethod = &m
+	}
+
+	if req.Confirmation != nil {
+		if err := req.Confirmation.Validate(); err != nil {
+			srv.writeValidationError(w, err)
+			return
+		}
+		confirmation := *req.Confirmation
+		switch confirmation.Type {
+		case yacheckout.Redirect, yacheckout.MobileApplication:
+			confirmation.ConfirmationURL = srv.URL + "/checkout/payments/v2/contract?orderId=" + payment.ID
+		case yacheckout.Embedded:
+			confirmation.ConfirmationToken = "ct-" + payment.ID
+		case yacheckout.QR:
+			confirmation.ConfirmationData = "https://qr.nspk.ru/" + payment.ID
+		}
+		payment.Confirmation = &confirmation
+	}
+
+	setTransfersStatus(payment)
+	srv.payments[payment.ID] = payment
+	srv.paymentIDs = append(srv.paymentIDs, payment.ID)
+
+	if req.PaymentMethodID != "" {
+		if srv.revoked[req.PaymentMethodID] {
+			srv.cancel(payment, yacheckout.YandexCheckout, yacheckout.PermissionRevoked)
+		} else {
+			srv.authorize(payment)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, payment)
+}
+
+func (srv *Server) getPayment(w http.ResponseWriter, id string) {
+
+	payment, ok := srv.payments[id]
+	if !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Payment doesn't exist", "payment_id")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payment)
+}
+
+func (srv *Server) listPayments(w http.ResponseWriter, r *http.Request) {
+
+	q := r.URL.Query()
+	var items []yacheckout.Payment
+
+	for i := len(srv.paymentIDs) - 1; i >= 0; i-- {
+		p := srv.payments[srv.paymentIDs[i]]
+		switch {
+		case first(q, "status") != "" && string(p.Status) != first(q, "status"),
+			first(q, "payment_method") != "" && (p.PaymentMethod == nil || p.PaymentMethod.Type != first(q, "payment_method")),
+			!matchTime(q, "created_at", p.CreatedAt),
+			!matchTime(q, "captured_at", p.CapturedAt):
+			continue
+		}
+		items = append(items, *p)
+	}
+
+	pg, ok := paginate(q, len(items))
+	if !ok {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Invalid limit or cursor", "cursor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &yacheckout.Payments{Type: "list", Items: append([]yacheckout.Payment{}, items[pg.from:pg.to]...), NextCursor: pg.next})
+}
+
+func (srv *Server) capturePayment(w http.ResponseWriter, id string, body []byte) {
+
+	payment, ok := srv.payments[id]
+	if !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Payment doesn't exist", "payment_id")
+		return
+	}
+
+	var req yacheckout.Payment
+	if len(body) > 0 && string(body) != "null" && !srv.decode(w, body, &req) {
+		return
+	}
+
+	if !payment.Status.Allows(yacheckout.CaptureOperation) {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Payment is in "+string(payment.Status)+" status", "payment_id")
+		return
+	}
+
+	if req.Amount != nil {
+		if cmp, err := req.Amount.Cmp(*payment.Amount); err != nil || cmp > 0 || req.Amount.Sign() <= 0 {
+			srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Capture amount must not exceed payment amount", "amount")
+			return
+		}
+		payment.Amount = req.Amount
+	}
+
+	if len(req.Transfers) > 0 {
+		if err := yacheckout.ValidateTransfers(req.Transfers, *payment.Amount); err != nil {
+			srv.writeValidationError(w, err)
+			return
+		}
+		payment.Transfers = req.Transfers
+	} else if req.Amount != nil && len(payment.Transfers) > 0 {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Transfers are required for partial capture", "transfers")
+		return
+	}
+
+	if req.Receipt != nil {
+		payment.Receipt = req.Receipt
+	}
+
+	srv.succeed(payment)
+	writeJSON(w, http.StatusOK, payment)
+}
+
+func (srv *Server) cancelPayment(w http.ResponseWriter, id string) {
+
+	payment, ok := srv.payments[id]
+	if !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Payment doesn't exist", "payment_id")
+		return
+	}
+
+	if !payment.Status.Allows(yacheckout.CancelOperation) {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Payment is in "+string(payment.Status)+" status", "payment_id")
+		return
+	}
+
+	srv.cancel(payment, yacheckout.Merchant, yacheckout.CanceledByMerchant)
+	writeJSON(w, http.StatusOK, payment)
+}
+
+//authorize func moves payment to waiting_for_capture or succeeded unless Decline return reason
+func (srv *Server) authorize(payment *yacheckout.Payment) {
+
+	if srv.Decline != nil {
+		if reason := srv.Decline(payment); reason != "" {
+			srv.cancel(payment, yacheckout.PaymentNetwork, reason)
+			return
+		}
+	}
+
+	payment.Paid = true
+	payment.AuthorizationDetails = &yacheckout.AuthorizationDetails{RRN: payment.ID[:12], AuthCode: "000000"}
+
+	if payment.SavePaymentMethod && payment.PaymentMethod != nil {
+		payment.PaymentMethod.Saved = true
+		method := *payment.PaymentMethod
+		srv.methods[method.ID] = &method
+	}
+
+	if payment.Capture {
+		srv.succeed(payment)
+		return
+	}
+
+	payment.Status = yacheckout.WaitingForCapture
+	expires := srv.now().AddDate(0, 0, 7)
+	payment.ExpiresAt = &expires
+	setTransfersStatus(payment)
+}
+
+func (srv *Server) succeed(payment *yacheckout.Payment) {
+
+	payment.Status = yacheckout.Succeeded
+	payment.Paid = true
+	payment.Refundable = true
+	payment.CapturedAt = srv.now()
+	payment.ExpiresAt = nil
+	refunded := yacheckout.NewAmount(0, payment.Amount.Currency)
+	payment.RefundedAmount = &refunded
+	setTransfersStatus(payment)
+
+	if payment.Deal != nil {
+		if deal, ok := srv.deals[payment.Deal.ID]; ok {
+			moveDeal(deal, *payment.Amount, settlementsTotal(payment.Deal.Settlements, payment.Amount.Currency))
+		}
+	}
+
+	if payment.Receipt != nil {
+		payment.ReceiptRegistration = string(yacheckout.Succeeded)
+		srv.issueReceipt(payment.Receipt, "payment", payment.ID, "")
+	}
+}
+
+func (srv *Server) cancel(payment *yacheckout.Payment, party, reason string) {
+
+	payment.Status = yacheckout.Canceled
+	payment.Paid = false
+	payment.ExpiresAt = nil
+	payment.CancellationDetails = &yacheckout.CancellationDetails{Party: party, Reason: reason}
+	setTransfersStatus(payment)
+}
+
+//setTransfersStatus func copies transfers of payment with its status
+func setTransfersStatus(payment *yacheckout.Payment) {
+
+	transfers := make([]yacheckout.Transfer, len(payment.Transfers))
+	for i, transfer := range payment.Transfers {
+		transfer.Status = string(payment.Status)
+		transfers[i] = transfer
+	}
+
+	if len(transfers) > 0 {
+		payment.Transfers = transfers
+	}
+}
+
+//paymentMethodOf func return payment_method object of data as API does, card number is masked
+func paymentMethodOf(data yacheckout.PaymentMethodData) *yacheckout.PaymentMethod {
+
+	method := &yacheckout.PaymentMethod{}
+	if b, err := json.Marshal(data); err != nil || json.Unmarshal(b, method) != nil {
+		return &yacheckout.PaymentMethod{Type: data.PaymentMethodType()}
+	}
+
+	if method.Card != nil {
+		card := *method.Card
+		number := strconv.FormatUint(card.Number, 10)
+		if len(number) >= 10 {
+			first6, _ := strconv.ParseUint(number[:6], 10, 32)
+			card.First6, card.Last4 = uint32(first6), number[len(number)-4:]
+		}
+		card.Number, card.CSC = 0, ""
+		method.Card = &card
+	}
+
+	return method
+}
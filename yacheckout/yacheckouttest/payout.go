@@ -0,0 +1,94 @@
+package yacheckouttest
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Payout func return copy of payout stored by srv or nil
+func (srv *Server) Payout(id string) *yacheckout.Payout {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if payout, ok := srv.payouts[id]; ok {
+		p := *payout
+		return &p
+	}
+
+	return nil
+}
+
+func (srv *Server) createPayout(w http.ResponseWriter, body []byte) {
+
+	var req yacheckout.Payout
+	if !srv.decode(w, body, &req) {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		srv.writeValidationError(w, err)
+		return
+	}
+
+	var deal *yacheckout.Deal
+	if req.Deal != nil {
+		var ok bool
+		if deal, ok = srv.openDeal(w, req.Deal); !ok {
+			return
+		}
+		if cmp, err := req.Amount.Cmp(*deal.PayoutBalance); err != nil || cmp > 0 {
+			srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Amount exceeds deal payout balance", "amount")
+			return
+		}
+	}
+
+	payout := &yacheckout.Payout{
+		ID:          "po-" + uuid.New().String(),
+		Amount:      req.Amount,
+		Status:      yacheckout.PayoutStatusSucceeded,
+		Description: req.Description,
+		CreatedAt:   srv.now(),
+		Deal:        req.Deal,
+		Metadata:    req.Metadata,
+		Test:        true,
+	}
+
+	if req.PayoutDestinationData != nil {
+		destination := *req.PayoutDestinationData
+		if card := destination.Card; card != nil {
+			destination.Card = &yacheckout.PayoutCard{First6: card.Number[:6], Last4: card.Number[len(card.Number)-4:], CardType: yacheckout.Unknown}
+		}
+		destination.RecipientChecked = destination.Type == yacheckout.SBP
+		payout.PayoutDestination = &destination
+	} else {
+		payout.PayoutDestination = &yacheckout.PayoutDestination{Type: yacheckout.BankCard, Card: &yacheckout.PayoutCard{CardType: yacheckout.Unknown}}
+	}
+
+	if srv.DeclinePayout != nil {
+		if reason := srv.DeclinePayout(payout); reason != "" {
+			payout.Status = yacheckout.PayoutStatusCanceled
+			payout.CancellationDetails = &yacheckout.CancellationDetails{Party: yacheckout.YooMoney, Reason: reason}
+		}
+	}
+
+	if deal != nil && payout.Status == yacheckout.PayoutStatusSucceeded {
+		moveDeal(deal, req.Amount.Neg(), req.Amount.Neg())
+	}
+
+	srv.payouts[payout.ID] = payout
+	writeJSON(w, http.StatusOK, payout)
+}
+
+func (srv *Server) getPayout(w http.ResponseWriter, id string) {
+
+	payout, ok := srv.payouts[id]
+	if !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Payout doesn't exist", "payout_id")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payout)
+}
@@ -0,0 +1,66 @@
+package yacheckouttest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+func TestPayouts(t *testing.T) {
+
+	srv := NewServer(100500, "test_secret")
+	defer srv.Close()
+	srv.DeclinePayout = func(payout *yacheckout.Payout) string {
+		if payout.Amount.Minor > 100000 {
+			return yacheckout.OneTimeLimitExceeded
+		}
+		return ""
+	}
+	checkout := srv.Checkout()
+
+	tests := []struct {
+		name        string
+		amount      int64
+		destination *yacheckout.PayoutDestination
+		status      yacheckout.PayoutStatus
+	}{
+		{"bank card", 10000, yacheckout.NewBankCardDestination("5555555555554477"), yacheckout.PayoutStatusSucceeded},
+		{"yoomoney", 10000, yacheckout.NewYooMoneyDestination("41001614575714"), yacheckout.PayoutStatusSucceeded},
+		{"sbp", 10000, yacheckout.NewSBPDestination("79000000000", "100000000111"), yacheckout.PayoutStatusSucceeded},
+		{"declined", 100001, yacheckout.NewYooMoneyDestination("41001614575714"), yacheckout.PayoutStatusCanceled},
+	}
+
+	for _, tt := range tests {
+		amount := yacheckout.NewAmount(tt.amount, "RUB")
+		key := uuid.New()
+		payout, apierr, err := checkout.CreatePayout(srv.Client(), &key, &yacheckout.Payout{Amount: &amount, PayoutDestinationData: tt.destination})
+		if err != nil || apierr != nil || payout.Status != tt.status || payout.PayoutDestination == nil || payout.PayoutDestination.Type != tt.destination.Type {
+			t.Errorf("%s: CreatePayout = %+v, %v, %v", tt.name, payout, apierr, err)
+			continue
+		}
+
+		if card := payout.PayoutDestination.Card; card != nil && (card.Number != "" || card.Last4 != "4477") {
+			t.Errorf("%s: card of payout = %+v", tt.name, card)
+		}
+
+		if tt.status == yacheckout.PayoutStatusCanceled && (payout.CancellationDetails == nil || payout.CancellationDetails.Reason != yacheckout.OneTimeLimitExceeded) {
+			t.Errorf("%s: cancellation details = %+v", tt.name, payout.CancellationDetails)
+		}
+
+		got, apierr, err := checkout.GetPayout(srv.Client(), payout.ID)
+		if err != nil || apierr != nil || got.ID != payout.ID || got.Status != payout.Status {
+			t.Errorf("%s: GetPayout = %+v, %v, %v", tt.name, got, apierr, err)
+		}
+	}
+
+	if _, apierr, _ := checkout.GetPayout(srv.Client(), "missing"); !errors.Is(apierr, yacheckout.ErrNotFound) {
+		t.Errorf("GetPayout of missing = %v", apierr)
+	}
+
+	key := uuid.New()
+	if _, _, err := checkout.CreatePayout(srv.Client(), &key, &yacheckout.Payout{PayoutToken: "token"}); !errors.Is(err, yacheckout.ErrInvalidRequest) {
+		t.Errorf("CreatePayout without amount = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package yacheckouttest
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Receipt func return copy of receipt stored by srv or nil
+func (srv *Server) Receipt(id string) *yacheckout.Receipt {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if receipt, ok := srv.receipts[id]; ok {
+		r := *receipt
+		return &r
+	}
+
+	return nil
+}
+
+func (srv *Server) createReceipt(w http.ResponseWriter, body []byte) {
+
+	var req yacheckout.Receipt
+	if !srv.decode(w, body, &req) {
+		return
+	}
+
+	switch {
+	case req.Type != "payment" && req.Type != "refund":
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Receipt type must be payment or refund", "type")
+		return
+	case len(req.Items) == 0:
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Receipt items are required", "items")
+		return
+	case req.Customer == nil || req.Customer.Email == "" && req.Customer.Phone == "":
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Customer email or phone is required", "customer")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, srv.issueReceipt(&req, req.Type, req.PaymentID, req.RefundID))
+}
+
+func (srv *Server) getReceipt(w http.ResponseWriter, id string) {
+
+	receipt, ok := srv.receipts[id]
+	if !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Receipt doesn't exist", "receipt_id")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, receipt)
+}
+
+func (srv *Server) listReceipts(w http.ResponseWriter, r *http.Request) {
+
+	q := r.URL.Query()
+	var items []yacheckout.Receipt
+
+	for i := len(srv.receiptIDs) - 1; i >= 0; i-- {
+		rc := srv.receipts[srv.receiptIDs[i]]
+		switch {
+		case first(q, "status") != "" && rc.Status != first(q, "status"),
+			first(q, "payment_id") != "" && rc.PaymentID != first(q, "payment_id"),
+			first(q, "refund_id") != "" && rc.RefundID != first(q, "refund_id"),
+			!matchTime(q, "created_at", rc.RegisteredAt):
+			continue
+		}
+		items = append(items, *rc)
+	}
+
+	pg, ok := paginate(q, len(items))
+	if !ok {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Invalid limit or cursor", "cursor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &yacheckout.Receipts{Type: "list", Items: append([]yacheckout.Receipt{}, items[pg.from:pg.to]...), NextCursor: pg.next})
+}
+
+//issueReceipt func registers copy of rcpt as succeeded fiscal receipt
+func (srv *Server) issueReceipt(rcpt *yacheckout.Receipt, typ, paymentID, refundID string) *yacheckout.Receipt {
+
+	receipt := *rcpt
+	receipt.ID = uuid.New().String()
+	receipt.Type = typ
+	receipt.PaymentID = paymentID
+	receipt.RefundID = refundID
+	receipt.Status = string(yacheckout.Succeeded)
+	receipt.RegisteredAt = srv.now()
+	receipt.FiscalDocumentNumber = receipt.ID[:4]
+	receipt.FiscalStorageNumber = "9288000100115785"
+	receipt.FiscalAttribute = "2617603921"
+	receipt.FiscalProviderID = "fd9e9404-eaca-4000-8ec9-dc228ead2345"
+
+	srv.receipts[receipt.ID] = &receipt
+	srv.receiptIDs = append(srv.receiptIDs, receipt.ID)
+
+	return &receipt
+}
@@ -0,0 +1,138 @@
+package yacheckouttest
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Refund func return copy of refund stored by srv or nil
+func (srv *Server) Refund(id string) *yacheckout.Refund {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if refund, ok := srv.refunds[id]; ok {
+		r := *refund
+		return &r
+	}
+
+	return nil
+}
+
+func (srv *Server) createRefund(w http.ResponseWriter, body []byte) {
+
+	var req yacheckout.Refund
+	if !srv.decode(w, body, &req) {
+		return
+	}
+
+	payment, ok := srv.payments[req.PaymentID]
+	if !ok {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Payment doesn't exist", "payment_id")
+		return
+	}
+
+	if !payment.Allows(yacheckout.RefundOperation) {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Payment is not refundable", "payment_id")
+		return
+	}
+
+	if req.Amount == nil || req.Amount.Sign() <= 0 {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Amount must be positive", "amount")
+		return
+	}
+
+	refunded, err := payment.RefundedAmount.Add(*req.Amount)
+	if err != nil {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Currency doesn't match payment", "amount.currency")
+		return
+	}
+
+	if cmp, _ := refunded.Cmp(*payment.Amount); cmp > 0 {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Amount exceeds refundable amount", "amount")
+		return
+	}
+
+	if len(req.Sources) > 0 {
+		if err := yacheckout.ValidateRefundSources(req.Sources, *req.Amount); err != nil {
+			srv.writeValidationError(w, err)
+			return
+		}
+	}
+
+	var deal *yacheckout.Deal
+	if req.Deal != nil {
+		if deal, ok = srv.openDeal(w, req.Deal); !ok {
+			return
+		}
+		if cmp, _ := settlementsTotal(req.Deal.RefundSettlements, req.Amount.Currency).Cmp(*deal.PayoutBalance); cmp > 0 {
+			srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Refund settlements exceed deal payout balance", "deal.refund_settlements")
+			return
+		}
+	}
+
+	refund := &yacheckout.Refund{
+		ID:          uuid.New().String(),
+		PaymentID:   payment.ID,
+		Status:      string(yacheckout.Succeeded),
+		CreatedAt:   srv.now(),
+		Amount:      req.Amount,
+		Description: req.Description,
+		Receipt:     req.Receipt,
+		Sources:     req.Sources,
+		Deal:        req.Deal,
+	}
+
+	srv.refunds[refund.ID] = refund
+	srv.refundIDs = append(srv.refundIDs, refund.ID)
+
+	payment.RefundedAmount = &refunded
+	if deal != nil {
+		moveDeal(deal, req.Amount.Neg(), settlementsTotal(req.Deal.RefundSettlements, req.Amount.Currency).Neg())
+	}
+	payment.Refundable = !refunded.Equal(*payment.Amount)
+
+	if refund.Receipt != nil {
+		srv.issueReceipt(refund.Receipt, "refund", payment.ID, refund.ID)
+	}
+
+	writeJSON(w, http.StatusOK, refund)
+}
+
+func (srv *Server) getRefund(w http.ResponseWriter, id string) {
+
+	refund, ok := srv.refunds[id]
+	if !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Refund doesn't exist", "refund_id")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, refund)
+}
+
+func (srv *Server) listRefunds(w http.ResponseWriter, r *http.Request) {
+
+	q := r.URL.Query()
+	var items []yacheckout.Refund
+
+	for i := len(srv.refundIDs) - 1; i >= 0; i-- {
+		rf := srv.refunds[srv.refundIDs[i]]
+		switch {
+		case first(q, "status") != "" && rf.Status != first(q, "status"),
+			first(q, "payment_id") != "" && rf.PaymentID != first(q, "payment_id"),
+			!matchTime(q, "created_at", rf.CreatedAt):
+			continue
+		}
+		items = append(items, *rf)
+	}
+
+	pg, ok := paginate(q, len(items))
+	if !ok {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Invalid limit or cursor", "cursor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &yacheckout.Refunds{Type: "list", Items: append([]yacheckout.Refund{}, items[pg.from:pg.to]...), NextCursor: pg.next})
+}
@@ -0,0 +1,419 @@
+//Package yacheckouttest provides in-process fake Yandex.Checkout API for tests
+package yacheckouttest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+//Server struct is fake Yandex.Checkout API listening on a local address
+type Server struct {
+	*httptest.Server
+
+	ShopID     int
+	SecretKey  string
+	OAuthToken string
+
+	//Now return current time of server, time.Now by default
+	Now func() time.Time
+	//Decline return cancellation reason for payment being authorized, empty reason approves it
+	Decline func(payment *yacheckout.Payment) string
+	//DeclinePayout return cancellation reason for payout being made, empty reason approves it
+	DeclinePayout func(payout *yacheckout.Payout) string
+
+	mu          sync.Mutex
+	payments    map[string]*yacheckout.Payment
+	refunds     map[string]*yacheckout.Refund
+	receipts    map[string]*yacheckout.Receipt
+	webhooks    map[string]*yacheckout.Webhook
+	payouts     map[string]*yacheckout.Payout
+	deals       map[string]*yacheckout.Deal
+	paymentIDs  []string
+	refundIDs   []string
+	receiptIDs  []string
+	webhookIDs  []string
+	dealIDs     []string
+	methods     map[string]*yacheckout.PaymentMethod
+	revoked     map[string]bool
+	idempotence map[string]*response
+	failures    []*Failure
+}
+
+//Failure struct is response injected instead of handling matching requests
+type Failure struct {
+	Method string            //HTTP method to match, empty matches any
+	Path   string            //path prefix to match such as "payments", empty matches any
+	Times  int               //number of requests to fail, 0 means one
+	Status int               //HTTP status of response
+	Error  *yacheckout.Error //body of response
+	Drop   bool              //close connection without response instead
+}
+
+//response struct is recorded response of idempotent request
+type response struct {
+	request [sha256.Size]byte
+	status  int
+	body    []byte
+}
+
+//NewServer func starts Server accepting shopID and secretKey credentials
+func NewServer(shopID int, secretKey string) *Server {
+
+	srv := &Server{
+		ShopID:      shopID,
+		SecretKey:   secretKey,
+		Now:         time.Now,
+		payments:    map[string]*yacheckout.Payment{},
+		refunds:     map[string]*yacheckout.Refund{},
+		receipts:    map[string]*yacheckout.Receipt{},
+		webhooks:    map[string]*yacheckout.Webhook{},
+		payouts:     map[string]*yacheckout.Payout{},
+		deals:       map[string]*yacheckout.Deal{},
+		methods:     map[string]*yacheckout.PaymentMethod{},
+		revoked:     map[string]bool{},
+		idempotence: map[string]*response{},
+	}
+
+	srv.Server = httptest.NewServer(http.HandlerFunc(srv.serveHTTP))
+	return srv
+}
+
+//Client func return http.Client sending requests for yacheckout.APIEndpoint to srv
+func (srv *Server) Client() *http.Client {
+	return &http.Client{Transport: &transport{srv: srv, next: srv.Server.Client().Transport}}
+}
+
+//Checkout func return yacheckout.Checkout with credentials accepted by srv and pointed at it
+func (srv *Server) Checkout(opts ...yacheckout.Option) *yacheckout.Checkout {
+
+	opts = append([]yacheckout.Option{
+		yacheckout.WithBaseURL(srv.URL + "/"),
+		yacheckout.WithHTTPClient(srv.Server.Client()),
+	}, opts...)
+
+	return yacheckout.NewCheckout(srv.ShopID, srv.SecretKey, srv.OAuthToken, opts...)
+}
+
+//Fail func injects failure into responses of srv
+func (srv *Server) Fail(failure Failure) {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if failure.Times <= 0 {
+		failure.Times = 1
+	}
+
+	if failure.Status == 0 {
+		failure.Status = http.StatusInternalServerError
+	}
+
+	srv.failures = append(srv.failures, &failure)
+}
+
+//transport struct rewrites yacheckout.APIEndpoint requests to Server
+type transport struct {
+	srv  *Server
+	next http.RoundTripper
+}
+
+//RoundTrip func implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if u := req.URL.String(); strings.HasPrefix(u, yacheckout.APIEndpoint) {
+		req = req.Clone(req.Context())
+		r, err := req.URL.Parse(t.srv.URL + "/" + strings.TrimPrefix(u, yacheckout.APIEndpoint))
+		if err != nil {
+			return nil, err
+		}
+		req.URL, req.Host = r, r.Host
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+func (srv *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Failed to read request body", "")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if failure := srv.failure(r.Method, path); failure != nil {
+		if failure.Drop {
+			if hj, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hj.Hijack(); err == nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+		apierr := failure.Error
+		if apierr == nil {
+			apierr = &yacheckout.Error{Type: "error", Code: yacheckout.InternalServerError}
+		}
+		writeJSON(w, failure.Status, apierr)
+		return
+	}
+
+	if !srv.authorized(r) {
+		srv.writeError(w, http.StatusUnauthorized, yacheckout.InvalidCredentials, "Login or password is incorrect", "")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		srv.route(w, r, path, body)
+		return
+	}
+
+	key := r.Header.Get("Idempotence-Key")
+	if key == "" {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Idempotence key is required", "Idempotence-Key")
+		return
+	}
+
+	sum := sha256.Sum256(append([]byte(r.Method+" "+path+"\n"), body...))
+	if res, ok := srv.idempotence[key]; ok {
+		if res.request != sum {
+			srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Idempotence key duplicated with another request", "Idempotence-Key")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(res.status)
+		w.Write(res.body)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	srv.route(rec, r, path, body)
+
+	if rec.Code != http.StatusAccepted && rec.Code < http.StatusInternalServerError {
+		srv.idempotence[key] = &response{request: sum, status: rec.Code, body: rec.Body.Bytes()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+func (srv *Server) route(w http.ResponseWriter, r *http.Request, path string, body []byte) {
+
+	parts := strings.Split(path, "/")
+
+	switch {
+	case r.Method == http.MethodPost && path == "payments":
+		srv.createPayment(w, body)
+	case r.Method == http.MethodGet && path == "payments":
+		srv.listPayments(w, r)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "payments":
+		srv.getPayment(w, parts[1])
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "payments" && parts[2] == "capture":
+		srv.capturePayment(w, parts[1], body)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "payments" && parts[2] == "cancel":
+		srv.cancelPayment(w, parts[1])
+	case r.Method == http.MethodPost && path == "refunds":
+		srv.createRefund(w, body)
+	case r.Method == http.MethodGet && path == "refunds":
+		srv.listRefunds(w, r)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "refunds":
+		srv.getRefund(w, parts[1])
+	case r.Method == http.MethodPost && path == "receipts":
+		srv.createReceipt(w, body)
+	case r.Method == http.MethodGet && path == "receipts":
+		srv.listReceipts(w, r)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "receipts":
+		srv.getReceipt(w, parts[1])
+	case r.Method == http.MethodPost && path == "webhooks":
+		srv.createWebhook(w, body)
+	case r.Method == http.MethodGet && path == "webhooks":
+		srv.listWebhooks(w)
+	case r.Method == http.MethodDelete && len(parts) == 2 && parts[0] == "webhooks":
+		srv.deleteWebhook(w, parts[1])
+	case r.Method == http.MethodPost && path == "payouts":
+		srv.createPayout(w, body)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "payouts":
+		srv.getPayout(w, parts[1])
+	case r.Method == http.MethodPost && path == "deals":
+		srv.createDeal(w, body)
+	case r.Method == http.MethodGet && path == "deals":
+		srv.listDeals(w, r)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "deals":
+		srv.getDeal(w, parts[1])
+	case r.Method == http.MethodGet && path == "me":
+		srv.getMe(w)
+	default:
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Unknown method "+r.Method+" /"+path, "")
+	}
+}
+
+//failure func return injected failure matching request and consumes it
+func (srv *Server) failure(method, path string) *Failure {
+
+	for i, f := range srv.failures {
+		if (f.Method == "" || f.Method == method) && strings.HasPrefix(path, f.Path) {
+			if f.Times--; f.Times <= 0 {
+				srv.failures = append(srv.failures[:i], srv.failures[i+1:]...)
+			}
+			return f
+		}
+	}
+
+	return nil
+}
+
+func (srv *Server) authorized(r *http.Request) bool {
+
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return srv.OAuthToken != "" && strings.TrimPrefix(auth, "Bearer ") == srv.OAuthToken
+	}
+
+	user, pass, ok := r.BasicAuth()
+	return ok && user == strconv.Itoa(srv.ShopID) && pass == srv.SecretKey
+}
+
+func (srv *Server) now() *time.Time {
+	t := srv.Now().UTC()
+	return &t
+}
+
+func (srv *Server) writeError(w http.ResponseWriter, status int, code, description, parameter string) {
+	writeJSON(w, status, &yacheckout.Error{
+		Type:        "error",
+		ID:          uuid.New().String(),
+		Code:        code,
+		Description: description,
+		Parameter:   parameter,
+	})
+}
+
+//writeValidationError func writes err of request validation as invalid_request error
+func (srv *Server) writeValidationError(w http.ResponseWriter, err error) {
+
+	parameter := ""
+	var verr *yacheckout.ValidationError
+	var errs yacheckout.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		parameter = verr.Parameter
+	case errors.As(err, &errs) && len(errs) > 0:
+		parameter = errs[0].Parameter
+	}
+
+	srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, err.Error(), parameter)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		status, b = http.StatusInternalServerError, []byte(`{"type":"error","code":"internal_server_error"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+//decode func unmarshals request body, it writes invalid_request error on failure
+func (srv *Server) decode(w http.ResponseWriter, body []byte, v interface{}) bool {
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(v); err != nil {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Invalid JSON: "+err.Error(), "")
+		return false
+	}
+
+	return true
+}
+
+//page struct is list window selected by limit and cursor
+type page struct {
+	from, to int
+	next     string
+}
+
+//paginate func selects window of n items by limit and cursor of query.
+//Cursor is offset of the first item in the list
+func paginate(q map[string][]string, n int) (p page, ok bool) {
+
+	limit := 10
+	if v := first(q, "limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 1 || l > 100 {
+			return p, false
+		}
+		limit = l
+	}
+
+	if v := first(q, "cursor"); v != "" {
+		from, err := strconv.Atoi(v)
+		if err != nil || from < 0 {
+			return p, false
+		}
+		p.from = from
+	}
+
+	if p.from > n {
+		p.from = n
+	}
+
+	p.to = p.from + limit
+	if p.to >= n {
+		p.to = n
+	} else {
+		p.next = strconv.Itoa(p.to)
+	}
+
+	return p, true
+}
+
+//matchTime func reports whether t satisfies name.gte, name.gt, name.lte and name.lt of query
+func matchTime(q map[string][]string, name string, t *time.Time) bool {
+
+	for _, op := range []string{"gte", "gt", "lte", "lt"} {
+		v := first(q, name+"."+op)
+		if v == "" {
+			continue
+		}
+		bound, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil || t == nil {
+			return false
+		}
+		switch {
+		case op == "gte" && t.Before(bound),
+			op == "gt" && !t.After(bound),
+			op == "lte" && t.After(bound),
+			op == "lt" && !t.Before(bound):
+			return false
+		}
+	}
+
+	return true
+}
+
+func first(q map[string][]string, key string) string {
+
+	if v := q[key]; len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,203 @@
+package yacheckouttest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+func newPayment(t *testing.T, srv *Server, checkout *yacheckout.Checkout, minor int64) *yacheckout.Payment {
+
+	t.Helper()
+
+	amount := yacheckout.NewAmount(minor, "RUB")
+	key := uuid.New()
+	payment, apierr, err := checkout.CreatePayment(srv.Client(), &key, &yacheckout.Payment{
+		Amount:       &amount,
+		Confirmation: yacheckout.NewRedirectConfirmation("https://example.com/return", false),
+	})
+	if err != nil || apierr != nil {
+		t.Fatalf("CreatePayment: %v, %v", apierr, err)
+	}
+
+	return payment
+}
+
+func TestPaymentLifecycle(t *testing.T) {
+
+	srv := NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+
+	payment := newPayment(t, srv, checkout, 10000)
+	if payment.Status != yacheckout.Pending || payment.Confirmation == nil || payment.Confirmation.ConfirmationURL == "" {
+		t.Fatalf("created payment = %+v", payment)
+	}
+
+	if !srv.ConfirmPayment(payment.ID) {
+		t.Fatal("ConfirmPayment failed")
+	}
+
+	payment, apierr, err := checkout.GetPayment(srv.Client(), payment.ID)
+	if err != nil || apierr != nil || payment.Status != yacheckout.WaitingForCapture || !payment.Paid {
+		t.Fatalf("confirmed payment = %+v, %v, %v", payment, apierr, err)
+	}
+
+	captured := yacheckout.NewAmount(6000, "RUB")
+	key := uuid.New()
+	payment, apierr, err = checkout.CapturePayment(srv.Client(), &key, payment.ID, &yacheckout.Payment{Amount: &captured})
+	if err != nil || apierr != nil || payment.Status != yacheckout.Succeeded || !payment.Amount.Equal(captured) {
+		t.Fatalf("captured payment = %+v, %v, %v", payment, apierr, err)
+	}
+
+	//known payment states would reject refund before request
+	tooMuch := yacheckout.NewAmount(6001, "RUB")
+	key = uuid.New()
+	if _, apierr, _ = checkout.With(yacheckout.WithPaymentStates(nil)).CreateRefund(srv.Client(), &key, &yacheckout.Refund{PaymentID: payment.ID, Amount: &tooMuch}); apierr == nil || apierr.Code != yacheckout.InvalidRequest {
+		t.Fatalf("refund over captured amount = %v", apierr)
+	}
+
+	key = uuid.New()
+	refund, apierr, err := checkout.CreateRefund(srv.Client(), &key, &yacheckout.Refund{PaymentID: payment.ID, Amount: &captured})
+	if err != nil || apierr != nil || refund.Status != "succeeded" || refund.PaymentID != payment.ID {
+		t.Fatalf("refund = %+v, %v, %v", refund, apierr, err)
+	}
+
+	if p := srv.Payment(payment.ID); p.Refundable || p.RefundedAmount == nil || !p.RefundedAmount.Equal(captured) {
+		t.Fatalf("refunded payment = %+v", p)
+	}
+}
+
+func TestListPaymentsPagination(t *testing.T) {
+
+	srv := NewServer(100500, "test_secret")
+	defer srv.Close()
+	checkout := srv.Checkout()
+
+	created := map[string]bool{}
+	for i := 1; i <= 5; i++ {
+		created[newPayment(t, srv, checkout, int64(i)*100).ID] = true
+	}
+
+	filter := &yacheckout.PaymentsFilter{Limit: 2}
+	var pages []int
+	for {
+		payments, apierr, err := checkout.ListPayments(srv.Client(), filter)
+		if err != nil || apierr != nil {
+			t.Fatalf("ListPayments: %v, %v", apierr, err)
+		}
+		pages = append(pages, len(payments.Items))
+		if payments.NextCursor == "" {
+			break
+		}
+		filter.Cursor = payments.NextCursor
+	}
+
+	if len(pages) != 3 || pages[0] != 2 || pages[1] != 2 || pages[2] != 1 {
+		t.Fatalf("page sizes = %v; want [2 2 1]", pages)
+	}
+
+	it := checkout.IteratePayments(srv.Client(), &yacheckout.PaymentsFilter{Limit: 2})
+	seen := map[string]bool{}
+	for it.Next(context.Background()) {
+		seen[it.Payment().ID] = true
+	}
+
+	if it.Err() != nil || len(seen) != len(created) {
+		t.Fatalf("iterated %d of %d payments: %v", len(seen), len(created), it.Err())
+	}
+
+	for id := range created {
+		if !seen[id] {
+			t.Fatalf("payment %s not iterated", id)
+		}
+	}
+}
+
+func TestFailRetry(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		failure Failure
+		ok      bool
+	}{
+		{"retried 500", Failure{Method: http.MethodPost, Path: "payments", Status: http.StatusInternalServerError, Times: 2}, true},
+		{"retried processing", Failure{Path: "payments", Status: http.StatusAccepted, Times: 2, Error: &yacheckout.Error{Type: "processing", RetryAfter: 1}}, true},
+		{"dropped connection", Failure{Path: "payments", Drop: true, Times: 1}, true},
+		{"attempts exhausted", Failure{Path: "payments", Status: http.StatusInternalServerError, Times: 3}, false},
+		{"not retried 400", Failure{Path: "payments", Status: http.StatusBadRequest, Error: &yacheckout.Error{Type: "error", Code: yacheckout.InvalidRequest}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			srv := NewServer(100500, "test_secret")
+			defer srv.Close()
+			checkout := srv.Checkout()
+			checkout.Retry = &yacheckout.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+			srv.Fail(tt.failure)
+
+			amount := yacheckout.NewAmount(10000, "RUB")
+			key := uuid.New()
+			payment, apierr, err := checkout.CreatePayment(srv.Client(), &key, &yacheckout.Payment{
+				Amount:       &amount,
+				Confirmation: yacheckout.NewRedirectConfirmation("https://example.com/return", false),
+			})
+
+			if ok := err == nil && apierr == nil; ok != tt.ok {
+				t.Fatalf("CreatePayment = %+v, %v, %v; want success %v", payment, apierr, err, tt.ok)
+			}
+
+			if tt.ok && srv.Payment(payment.ID) == nil {
+				t.Fatalf("payment %s not stored", payment.ID)
+			}
+		})
+	}
+
+	srv := NewServer(100500, "test_secret")
+	defer srv.Close()
+	if _, apierr, _ := srv.Checkout().GetPayment(srv.Client(), "missing"); !errors.Is(apierr, yacheckout.ErrNotFound) {
+		t.Fatalf("GetPayment of missing = %v", apierr)
+	}
+}
+
+func TestCreatePaymentValidation(t *testing.T) {
+
+	srv := NewServer(100500, "test_secret")
+	defer srv.Close()
+
+	key := uuid.New()
+	body := []byte(`{"amount":{"value":"100.00","currency":"RUB"},"confirmation":{"type":"redirect"}}`)
+	_, apierr, err := srv.Checkout().Exec("", srv.Client(), http.MethodPost, &key, "payments", body)
+	if err != nil || apierr == nil || apierr.Code != yacheckout.InvalidRequest || apierr.Parameter != "confirmation.return_url" {
+		t.Fatalf("CreatePayment without return_url = %v, %v", apierr, err)
+	}
+}
+
+func TestCreatePaymentMethodData(t *testing.T) {
+
+	srv := NewServer(100500, "test_secret")
+	defer srv.Close()
+
+	amount := yacheckout.NewAmount(10000, "RUB")
+	key := uuid.New()
+	payment, apierr, err := srv.Checkout().CreatePayment(srv.Client(), &key, &yacheckout.Payment{
+		Amount:            &amount,
+		PaymentMethodData: &yacheckout.BankCardData{Card: &yacheckout.Card{Number: 5555555555554477, ExpiryYear: 2030, ExpiryMonth: "07", CSC: "123"}},
+		Confirmation:      yacheckout.NewRedirectConfirmation("https://example.com/return", false),
+	})
+	if err != nil || apierr != nil {
+		t.Fatalf("CreatePayment: %v, %v", apierr, err)
+	}
+
+	data, err := payment.PaymentMethod.Data()
+	card, ok := data.(*yacheckout.BankCardData)
+	if err != nil || !ok || card.Card == nil || card.Card.First6 != 555555 || card.Card.Last4 != "4477" || card.Card.Number != 0 || card.Card.CSC != "" {
+		t.Fatalf("payment method data = %#v, %v", data, err)
+	}
+}
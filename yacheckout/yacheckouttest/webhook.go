@@ -0,0 +1,69 @@
+package yacheckouttest
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/impnumb/yandex-checkout-sdk-go/yacheckout"
+)
+
+func (srv *Server) createWebhook(w http.ResponseWriter, body []byte) {
+
+	var req yacheckout.Webhook
+	if !srv.decode(w, body, &req) {
+		return
+	}
+
+	switch req.Event {
+	case yacheckout.PaymentWaitingForCapture, yacheckout.PaymentSucceeded, yacheckout.PaymentCanceled, yacheckout.RefundSucceeded:
+	default:
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "Unknown event", "event")
+		return
+	}
+
+	if req.URL == "" {
+		srv.writeError(w, http.StatusBadRequest, yacheckout.InvalidRequest, "URL is required", "url")
+		return
+	}
+
+	for _, id := range srv.webhookIDs {
+		if webhook := srv.webhooks[id]; webhook.Event == req.Event && webhook.URL == req.URL {
+			writeJSON(w, http.StatusOK, webhook)
+			return
+		}
+	}
+
+	webhook := &yacheckout.Webhook{ID: "wh-" + uuid.New().String(), Event: req.Event, URL: req.URL}
+	srv.webhooks[webhook.ID] = webhook
+	srv.webhookIDs = append(srv.webhookIDs, webhook.ID)
+
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+func (srv *Server) listWebhooks(w http.ResponseWriter) {
+
+	webhooks := &yacheckout.Webhooks{Type: "list", Items: []yacheckout.Webhook{}}
+	for _, id := range srv.webhookIDs {
+		webhooks.Items = append(webhooks.Items, *srv.webhooks[id])
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+func (srv *Server) deleteWebhook(w http.ResponseWriter, id string) {
+
+	if _, ok := srv.webhooks[id]; !ok {
+		srv.writeError(w, http.StatusNotFound, yacheckout.NotFound, "Webhook doesn't exist", "webhook_id")
+		return
+	}
+
+	delete(srv.webhooks, id)
+	for i, wid := range srv.webhookIDs {
+		if wid == id {
+			srv.webhookIDs = append(srv.webhookIDs[:i], srv.webhookIDs[i+1:]...)
+			break
+		}
+	}
+
+	writeJSON(w, http.StatusOK, struct{}{})
+}